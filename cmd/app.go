@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(AppCmd)
+}
+
+var AppCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Time the application's execution and commit of each block",
+	Long: `For a given node, app lists every committed height with the time consensus took next to the time the application took, so a slow app can be told apart from a slow network.
+
+	consensus    from entering the height's first round until entering commit
+	execute      from finalizing the commit until the block's txs were delivered (BeginBlock, DeliverTx, EndBlock)
+	commit       from then until the app committed its state
+	txs          valid/invalid, as the app judged them
+	app hash     the hash the app returned on commit, marked (same) if it didn't change from the last height
+
+	Heights the app hasn't finished show no times.
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		heights, err := reader.GetHeights(entries, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		blocks, err := reader.GetAppBlocks(entries)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		consensus := make(map[int]reader.Height)
+		for _, height := range heights {
+			consensus[height.Height] = height
+		}
+
+		for _, block := range blocks {
+			hash := block.AppHash
+			if !block.HashChanged && hash != "" {
+				hash += " (same)"
+			}
+
+			fmt.Printf("%s %s height %d: consensus %v, execute %v, commit %v, txs %d/%d, app hash %s\n", block.Date, block.Time, block.Height, consensus[block.Height].Duration, block.Execute, block.Commit, block.ValidTxs, block.InvalidTxs, hash)
+		}
+	},
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var threshold *int
+
+func init() {
+	RootCmd.AddCommand(DoctorCmd)
+
+	threshold = DoctorCmd.PersistentFlags().Int("threshold", 3000, "miliseconds a height may take before it is flagged as slow")
+
+	viper.BindPFlag("doctor.threshold", DoctorCmd.PersistentFlags().Lookup("threshold"))
+}
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Scan a node's log for consensus problems",
+	Long: `For a given node, doctor scans the entire log and lists consensus anomalies, most severe first, each with the node's state at the moment it was found.
+
+	Severity:
+	4 = stalled (height never committed, after changing rounds or taking longer than --threshold)
+	3 = slow height (longer than --threshold), or precommit entered without +2/3 prevotes
+	2 = round change (round > 0)
+	1 = timeout fired
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		findings, err := reader.Diagnose(entries, nodes, *threshold)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for i, finding := range findings {
+			fmt.Printf("%d. [%d] %s %s %s: %s\n", i+1, finding.Severity, finding.Date, finding.Time, finding.Kind, finding.Detail)
+			fmt.Println(finding.Status)
+		}
+	},
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var exporterAddr *string
+
+func init() {
+	RootCmd.AddCommand(ExporterCmd)
+
+	exporterAddr = ExporterCmd.PersistentFlags().String("addr", ":26660", "address to serve /metrics on")
+
+	viper.BindPFlag("exporter.addr", ExporterCmd.PersistentFlags().Lookup("addr"))
+}
+
+var ExporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve Prometheus metrics derived from a node's log",
+	Long: `For nodes without Tendermint metrics enabled, exporter follows a log that's still being written (raw or rendered, surviving rotation and truncation) and serves metrics derived from it at /metrics:
+
+	tendermint_consensus_height                             current height
+	tendermint_consensus_rounds                             current round
+	tendermint_consensus_step{step}                         1 for the current step, 0 for the rest
+	tendermint_consensus_height_duration_seconds            histogram of time from a height's first round to its commit
+	tendermint_consensus_rounds_per_height                  histogram of rounds taken to commit a height
+	tendermint_consensus_proposal_latency_seconds           histogram of time from entering a round to receiving its proposal
+	tendermint_consensus_votes_received_total{validator,type}   distinct votes received from each validator (not counting copies relayed by other peers)
+	tendermint_p2p_peer_msgs_received_total{peer}           msgs received from each peer
+	t_logs_parse_errors_total                               log reads and entries that couldn't be parsed (each is logged and skipped)
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		tail, err := filefuncs.OpenTail(logName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer tail.Close()
+
+		metrics := newLogMetrics()
+
+		http.Handle("/metrics", promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{}))
+		go func() {
+			log.Fatal(http.ListenAndServe(*exporterAddr, nil))
+		}()
+
+		fmt.Println("serving metrics for", logName, "on", *exporterAddr)
+
+		observer := reader.NewObserver(nodes)
+
+		for {
+			//a bad read or entry is logged and counted rather than taking the exporter down; the entries read before it are
+			//still observed, and the next poll picks up from there
+			entries, err := tail.Next()
+			if err != nil {
+				log.Println(err)
+				metrics.parseErrors.Inc()
+			}
+
+			for _, entry := range entries {
+				observations, err := observer.Add(entry)
+				if err != nil {
+					log.Println("skipping", entry.Date, entry.Time, entry.Descrip+":", err)
+					metrics.parseErrors.Inc()
+					continue
+				}
+				metrics.observe(observations)
+			}
+			metrics.setStatus(observer.Follower.Status)
+
+			time.Sleep(followPoll)
+		}
+	},
+}
+
+//consensus steps, as Status names them
+var steps = []string{"NewRound", "Propose", "Prevote", "Precommit", "Commit"}
+
+type logMetrics struct {
+	registry        *prometheus.Registry
+	height          prometheus.Gauge
+	round           prometheus.Gauge
+	step            *prometheus.GaugeVec
+	heightDuration  prometheus.Histogram
+	rounds          prometheus.Histogram
+	proposalLatency prometheus.Histogram
+	votes           *prometheus.CounterVec
+	msgs            *prometheus.CounterVec
+	parseErrors     prometheus.Counter
+}
+
+func newLogMetrics() *logMetrics {
+	metrics := &logMetrics{
+		registry: prometheus.NewRegistry(),
+		height: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "height",
+			Help: "Current height.",
+		}),
+		round: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "rounds",
+			Help: "Current round.",
+		}),
+		step: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "step",
+			Help: "1 for the current step, 0 for the rest.",
+		}, []string{"step"}),
+		heightDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "height_duration_seconds",
+			Help:    "Time from a height's first round to its commit.",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 10),
+		}),
+		rounds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "rounds_per_height",
+			Help:    "Rounds taken to commit a height.",
+			Buckets: []float64{1, 2, 3, 4, 5, 10},
+		}),
+		proposalLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "proposal_latency_seconds",
+			Help:    "Time from entering a round to receiving its proposal.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+		}),
+		votes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "tendermint", Subsystem: "consensus", Name: "votes_received_total",
+			Help: "Distinct votes received from each validator.",
+		}, []string{"validator", "type"}),
+		msgs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "tendermint", Subsystem: "p2p", Name: "peer_msgs_received_total",
+			Help: "Msgs received from each peer.",
+		}, []string{"peer"}),
+		parseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "t_logs", Name: "parse_errors_total",
+			Help: "Log reads and entries that couldn't be parsed.",
+		}),
+	}
+
+	metrics.registry.MustRegister(metrics.height, metrics.round, metrics.step, metrics.heightDuration, metrics.rounds,
+		metrics.proposalLatency, metrics.votes, metrics.msgs, metrics.parseErrors)
+
+	return metrics
+}
+
+func (metrics *logMetrics) observe(observations []reader.Observation) {
+	for _, observation := range observations {
+		switch observation.Metric {
+		case reader.ObserveHeightDuration:
+			metrics.heightDuration.Observe(observation.Value)
+		case reader.ObserveRounds:
+			metrics.rounds.Observe(observation.Value)
+		case reader.ObserveProposalLatency:
+			metrics.proposalLatency.Observe(observation.Value)
+		case reader.ObserveVote:
+			metrics.votes.WithLabelValues(observation.Name, observation.Type).Add(observation.Value)
+		case reader.ObserveMsg:
+			metrics.msgs.WithLabelValues(observation.Name).Add(observation.Value)
+		}
+	}
+}
+
+func (metrics *logMetrics) setStatus(status reader.Status) {
+	metrics.height.Set(float64(status.Height))
+	metrics.round.Set(float64(status.Round))
+
+	for _, step := range steps {
+		value := 0.0
+		if step == status.Step {
+			value = 1
+		}
+		metrics.step.WithLabelValues(step).Set(value)
+	}
+}
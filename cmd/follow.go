@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//how often a followed log is checked for new lines
+const followPoll = 250 * time.Millisecond
+
+//clears the terminal line so it can be redrawn
+const clearLine = "\r\033[K"
+
+//followLog tails the --log file (raw or rendered), feeding every entry to a Follower. Once what's already written has been
+//read, draw is called after each batch of new entries with any msgs rows that batch closed
+func followLog(dur time.Duration, draw func(follower *reader.Follower, timeStamps []string, msgArr [][]string)) {
+	nodes, err := filefuncs.UnmarshalNodes()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tail, err := filefuncs.OpenTail(logName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer tail.Close()
+
+	follower := reader.NewFollower(nodes, dur)
+	caughtUp := false
+
+	for {
+		entries, err := tail.Next()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var timeStamps []string
+		var msgArr [][]string
+
+		for _, entry := range entries {
+			stamps, rows, err := follower.Add(entry)
+			if err != nil {
+				log.Fatal(err)
+			}
+			timeStamps = append(timeStamps, stamps...)
+			msgArr = append(msgArr, rows...)
+		}
+
+		//history is only read to catch up, so only the latest closed row is drawn the first time around
+		if !caughtUp && len(msgArr) > 0 {
+			timeStamps, msgArr = timeStamps[len(timeStamps)-1:], msgArr[len(msgArr)-1:]
+		}
+
+		if !caughtUp || len(entries) > 0 {
+			draw(follower, timeStamps, msgArr)
+		}
+		caughtUp = true
+
+		time.Sleep(followPoll)
+	}
+}
+
+//followState redraws the node's current state whenever it changes
+func followState() {
+	var last string
+
+	followLog(0, func(follower *reader.Follower, timeStamps []string, msgArr [][]string) {
+		status := fmt.Sprint(follower.Status)
+		if status != last {
+			fmt.Print(clearLine + status)
+			last = status
+		}
+	})
+}
+
+//followMsgs prints each msgs row as its interval closes, redrawing the row for the current interval as msgs arrive
+func followMsgs(dur time.Duration) {
+	followLog(dur, func(follower *reader.Follower, timeStamps []string, msgArr [][]string) {
+		for i, msgs := range msgArr {
+			fmt.Println(clearLine+timeStamps[i], msgs)
+		}
+		fmt.Print(clearLine+follower.Interval, " ", follower.Msgs)
+	})
+}
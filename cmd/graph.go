@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var graphAt *string
+var graphFormat *string
+var graphWindow *string
+var graphSlow *string
+
+func init() {
+	RootCmd.AddCommand(GraphCmd)
+
+	graphAt = GraphCmd.PersistentFlags().String("at", "", "date and time to draw the graph at (01-01 00:00:00[.000])")
+	graphFormat = GraphCmd.PersistentFlags().String("format", "dot", "dot (Graphviz) or mermaid")
+	graphWindow = GraphCmd.PersistentFlags().String("window", "10s", "weigh edges by the messages received over this long, up to --at (eg. 500ms, 1m)")
+	graphSlow = GraphCmd.PersistentFlags().String("slow", "500ms", "highlight edges whose votes took longer than this to arrive")
+
+	viper.BindPFlag("graph.at", GraphCmd.PersistentFlags().Lookup("at"))
+	viper.BindPFlag("graph.format", GraphCmd.PersistentFlags().Lookup("format"))
+	viper.BindPFlag("graph.window", GraphCmd.PersistentFlags().Lookup("window"))
+	viper.BindPFlag("graph.slow", GraphCmd.PersistentFlags().Lookup("slow"))
+}
+
+var GraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Draw the validators' P2P graph as Graphviz or Mermaid",
+	Long: `graph writes the validators' P2P graph at a moment to stdout, named from nodes.json, for rendering with Graphviz (dot -Tsvg) or Mermaid.
+
+	Each edge a -- b is labelled with the messages b received from a and a received from b over --window (only ends with logs count), and drawn thicker the more there were.
+	The latency shown is the median time a validator's vote took to arrive straight from it (across machines, so clock skew counts).
+
+	red, dashed     connected, but no messages crossed in the window
+	grey, dotted    not connected at --at, but messages crossed earlier in the window
+	orange          latency over --slow
+
+  Takes any number of args: rendered log files (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if *graphAt == "" {
+			log.Fatal("--at is required (eg. --at '08-14 04:35:10')")
+		}
+
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		date, clock, err := splitTime(*graphAt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		at, err := reader.ParseTime(date, clock)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		window, err := parseInterval(*graphWindow)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		slow, err := parseInterval(*graphSlow)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logs, err := loadCluster(args, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		topology, err := reader.GetTopology(logs, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		flows, err := reader.GetFlows(topology, logs, nodes, at.Add(-window), at)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		switch *graphFormat {
+		case "dot":
+			dotGraph(os.Stdout, topology.Nodes, flows, slow)
+		case "mermaid":
+			mermaidGraph(os.Stdout, topology.Nodes, flows, slow)
+		default:
+			log.Fatal("--format must be dot or mermaid")
+		}
+	},
+}
+
+//edgeLabel describes a flow, eg. "120 / 98 msgs, 35ms"
+func edgeLabel(flow reader.Flow) string {
+	label := fmt.Sprintf("%d / %d msgs", flow.AtoB, flow.BtoA)
+	if flow.Samples > 0 {
+		label += ", " + flow.Latency.String()
+	}
+	return label
+}
+
+//edgeStyle picks how a flow is highlighted: "idle", "down", "slow", or "" for none
+func edgeStyle(flow reader.Flow, slow time.Duration) string {
+	switch {
+	case !flow.Connected:
+		return "down"
+	case flow.AtoB+flow.BtoA == 0:
+		return "idle"
+	case flow.Samples > 0 && flow.Latency > slow:
+		return "slow"
+	}
+	return ""
+}
+
+//edgeWidth scales with the edge's share of the busiest edge's messages, from 1 to 5
+func edgeWidth(flow reader.Flow, flows []reader.Flow) float64 {
+	max := 0
+	for _, other := range flows {
+		if other.AtoB+other.BtoA > max {
+			max = other.AtoB + other.BtoA
+		}
+	}
+	if max == 0 {
+		return 1
+	}
+	return 1 + 4*float64(flow.AtoB+flow.BtoA)/float64(max)
+}
+
+//dotGraph writes the graph for Graphviz
+func dotGraph(w io.Writer, names []string, flows []reader.Flow, slow time.Duration) {
+	styles := map[string]string{
+		"down": `, color=grey, style=dotted`,
+		"idle": `, color=red, style=dashed`,
+		"slow": `, color=orange`,
+	}
+
+	fmt.Fprintln(w, "graph validators {")
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%q;\n", name)
+	}
+	for _, flow := range flows {
+		fmt.Fprintf(w, "\t%q -- %q [label=%q, penwidth=%.1f%s];\n", flow.A, flow.B, edgeLabel(flow), edgeWidth(flow, flows), styles[edgeStyle(flow, slow)])
+	}
+	fmt.Fprintln(w, "}")
+}
+
+//mermaidGraph writes the graph as a Mermaid flowchart. Nodes get short ids, as names from nodes.json may be file names
+func mermaidGraph(w io.Writer, names []string, flows []reader.Flow, slow time.Duration) {
+	styles := map[string]string{
+		"down": "stroke:grey,stroke-dasharray:2",
+		"idle": "stroke:red,stroke-dasharray:5",
+		"slow": "stroke:orange",
+	}
+
+	ids := make(map[string]string)
+	fmt.Fprintln(w, "graph LR")
+	for i, name := range names {
+		ids[name] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(w, "\t%s[\"%s\"]\n", ids[name], strings.Replace(name, `"`, "#quot;", -1))
+	}
+	for i, flow := range flows {
+		fmt.Fprintf(w, "\t%s ---|\"%s\"| %s\n", ids[flow.A], edgeLabel(flow), ids[flow.B])
+
+		style := fmt.Sprintf("stroke-width:%.1fpx", edgeWidth(flow, flows))
+		if extra := styles[edgeStyle(flow, slow)]; extra != "" {
+			style += "," + extra
+		}
+		fmt.Fprintf(w, "\tlinkStyle %d %s\n", i, style)
+	}
+}
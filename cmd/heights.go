@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var heightsFrom *int
+var heightsTo *int
+var heightsFormat *string
+
+func init() {
+	RootCmd.AddCommand(HeightsCmd)
+
+	heightsFrom = HeightsCmd.PersistentFlags().Int("from", 0, "first height to show")
+	heightsTo = HeightsCmd.PersistentFlags().Int("to", 0, "last height to show")
+	heightsFormat = HeightsCmd.PersistentFlags().String("format", "text", "text, csv or json")
+
+	viper.BindPFlag("heights.from", HeightsCmd.PersistentFlags().Lookup("from"))
+	viper.BindPFlag("heights.to", HeightsCmd.PersistentFlags().Lookup("to"))
+	viper.BindPFlag("heights.format", HeightsCmd.PersistentFlags().Lookup("format"))
+}
+
+var HeightsCmd = &cobra.Command{
+	Use:   "heights",
+	Short: "Summarize each height a node committed, one row per height",
+	Long: `For a given node, heights prints a row for every height it committed, the first thing to look at when a chain slows down.
+
+	commit       when the node entered commit
+	interval     the time since it entered the last height's commit
+	rounds       how many rounds the height took
+	proposer     the proposer of the round that committed
+	txs          the txs in the block
+	steps        time spent in NewRound, Propose, Prevote, Precommit and Commit, summed over rounds (Commit lasts until the next height starts, so it includes timeout_commit)
+	!precommit   the node didn't sign a precommit in the round that committed
+
+	With --format csv or json, durations are in milliseconds.
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		rows, err := reader.GetHeightRows(entries, nodes, *heightsFrom, *heightsTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		switch *heightsFormat {
+		case "text":
+			for _, row := range rows {
+				var steps string
+				for _, step := range reader.HeightSteps {
+					steps += fmt.Sprintf(" %s=%v", step, row.Steps[step])
+				}
+
+				flag := ""
+				if row.NoPrecommit {
+					flag = " !precommit"
+				}
+				fmt.Printf("%s %s height %d: interval %v, rounds %d, proposer %s, txs %d,%s%s\n", row.Date, row.Commit, row.Height, row.Interval, row.Rounds, row.Proposer, row.Txs, steps, flag)
+			}
+
+		case "csv":
+			writer := csv.NewWriter(os.Stdout)
+
+			header := []string{"height", "date", "commit", "interval_ms", "rounds", "proposer", "txs"}
+			for _, step := range reader.HeightSteps {
+				header = append(header, step+"_ms")
+			}
+			writer.Write(append(header, "no_precommit"))
+
+			for _, row := range rows {
+				record := []string{strconv.Itoa(row.Height), row.Date, row.Commit, ms(row.Interval), strconv.Itoa(row.Rounds), row.Proposer, strconv.Itoa(row.Txs)}
+				for _, step := range reader.HeightSteps {
+					record = append(record, ms(row.Steps[step]))
+				}
+				writer.Write(append(record, strconv.FormatBool(row.NoPrecommit)))
+			}
+
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				log.Fatal(err)
+			}
+
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+
+			for _, row := range rows {
+				steps := make(map[string]float64)
+				for _, step := range reader.HeightSteps {
+					steps[step] = msFloat(row.Steps[step])
+				}
+				encoder.Encode(heightRow{row.Height, row.Date, row.Commit, msFloat(row.Interval), row.Rounds, row.Proposer, row.Txs, steps, row.NoPrecommit})
+			}
+
+		default:
+			log.Fatalf("unknown format %q: use text, csv or json", *heightsFormat)
+		}
+	},
+}
+
+type heightRow struct {
+	Height      int                `json:"height"`
+	Date        string             `json:"date"`
+	Commit      string             `json:"commit"`
+	Interval    float64            `json:"interval_ms"`
+	Rounds      int                `json:"rounds"`
+	Proposer    string             `json:"proposer"`
+	Txs         int                `json:"txs"`
+	Steps       map[string]float64 `json:"steps_ms"`
+	NoPrecommit bool               `json:"no_precommit"`
+}
+
+func msFloat(dur time.Duration) float64 {
+	return float64(dur) / float64(time.Millisecond)
+}
+
+func ms(dur time.Duration) string {
+	return strconv.FormatFloat(msFloat(dur), 'f', -1, 64)
+}
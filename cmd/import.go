@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var dbName *string
+
+func init() {
+	RootCmd.AddCommand(ImportCmd)
+
+	dbName = ImportCmd.PersistentFlags().String("db", "t-logs.sqlite", "SQLite database to write to (created if missing)")
+
+	viper.BindPFlag("import.db", ImportCmd.PersistentFlags().Lookup("db"))
+}
+
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Write parsed logs to a SQLite database for ad-hoc SQL",
+	Long: `import writes every entry from each node's log to a SQLite database, along with tables derived from them. Every table has the node's name and the entry's line (its index in the log), so derived rows join back to their entries.
+
+	entries       level, date, time, descrip, module, other (a JSON object: use json_extract(other, '$.key'))
+	votes         height, round, type, validator, nil, sent, peer (who it was received from)
+	proposals     height, round, event (turn, received, complete, invalid), proposer (for turn events)
+	block_parts   height, round, part, peer
+	steps         height, round, step, for each step the node entered
+	peers         event (Added peer, Stopping peer for error...), peer, ip, direction, err
+
+	Tables are indexed on date and time, and on height and round. Importing a node again replaces its rows.
+
+  Takes any number of args: rendered log files to import (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		db, err := sql.Open("sqlite3", *dbName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		for _, name := range clusterLogs(args, nodes) {
+			entries, err := loadEntries(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			records, err := reader.GetRecords(entries, nodes)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = filefuncs.ImportSQL(db, name, entries, records)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Println("imported", len(entries), "entries from", name)
+			if records.Skipped > 0 {
+				fmt.Println("skipped", records.Skipped, "votes that couldn't be read")
+			}
+		}
+	},
+}
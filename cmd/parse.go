@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/joshkestenberg/t-logs/filefuncs"
 	"github.com/joshkestenberg/t-logs/reader"
@@ -13,6 +16,11 @@ import (
 )
 
 var commits *bool
+var kinds *[]string
+var stateFollow *bool
+var snapshot *string
+var utcOffset *string
+var msgsFollow *bool
 
 func init() {
 	RootCmd.AddCommand(StateCmd)
@@ -20,8 +28,18 @@ func init() {
 	RootCmd.AddCommand(NodesCmd)
 
 	commits = MsgsCmd.PersistentFlags().Bool("commits", false, "add msgs indicating when blocks are committed")
+	kinds = MsgsCmd.PersistentFlags().StringSlice("kinds", nil, "count received msgs of these kinds (eg. Vote,HasVote, or all) instead of marking them")
+	stateFollow = StateCmd.PersistentFlags().Bool("follow", false, "follow a log that's still being written, redrawing the current state as it changes")
+	snapshot = StateCmd.PersistentFlags().String("snapshot", "", "compare with the node's /dump_consensus_state output saved in this file")
+	utcOffset = StateCmd.PersistentFlags().String("utc-offset", "", "with --snapshot, how far the node's log clock is from UTC (eg. -4h, 5h30m), to find the dump's time in the log")
+	msgsFollow = MsgsCmd.PersistentFlags().Bool("follow", false, "follow a log that's still being written, printing msgs rows as they close")
 
 	viper.BindPFlag("commits", MsgsCmd.PersistentFlags().Lookup("commits"))
+	viper.BindPFlag("msgs.kinds", MsgsCmd.PersistentFlags().Lookup("kinds"))
+	viper.BindPFlag("state.follow", StateCmd.PersistentFlags().Lookup("follow"))
+	viper.BindPFlag("state.snapshot", StateCmd.PersistentFlags().Lookup("snapshot"))
+	viper.BindPFlag("state.utc-offset", StateCmd.PersistentFlags().Lookup("utc-offset"))
+	viper.BindPFlag("msgs.follow", MsgsCmd.PersistentFlags().Lookup("follow"))
 
 }
 
@@ -36,8 +54,23 @@ var StateCmd = &cobra.Command{
 	Y = received nil vote
 	N = sent nil vote
 
-  Takes two args: date (01-01), and time (00:00:00[.000 if you'd like more specificity])`,
+	Below the state, any timeouts that fired in the node's current round are listed (the commit timeout with the height it precedes).
+
+	With --follow, state tails a log that's still being written (raw or rendered, surviving rotation and truncation) and redraws the node's current state as it changes.
+
+	With --snapshot, state reads the JSON the node's /dump_consensus_state RPC returned, rebuilds the state from the log at the date and time given, and lists where the two disagree. The dump's times are in UTC while the log's are on the node's clock, so instead of a date and time, --utc-offset may give how far the node's clock is from UTC, and the log is read at the time the dump was taken. The dump can't tell the node's own votes from others', so sent votes are compared as received. A node waiting out timeout_commit is at NewHeight in the dump, but still in the last height's Commit in the log.
+
+  Takes two args: date (01-01), and time (00:00:00[.000 if you'd like more specificity]); none with --follow; or none with --snapshot and --utc-offset`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if *stateFollow {
+			followState()
+			return
+		}
+
+		if *snapshot != "" {
+			compareSnapshot(args)
+			return
+		}
 
 		if len(args) != 2 {
 			log.Fatal("2 args required: date (01-01), and time (00:00:00[.000 if you'd like more specificity])")
@@ -46,27 +79,31 @@ var StateCmd = &cobra.Command{
 		date := args[0]
 		time := args[1]
 
-		file, err := filefuncs.OpenLog(logName)
+		entries, err := loadRange(date, time, date, time, true)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		entries, err := filefuncs.UnmarshalLines(file)
+		nodes, err := filefuncs.UnmarshalNodes()
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		nodes, err := filefuncs.UnmarshalNodes()
+		status, err := reader.GetStatus(entries, nodes, date, time)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		status, err := reader.GetStatus(entries, nodes, date, time)
+		fmt.Println(status)
+
+		timeouts, err := reader.TimeoutsAt(entries, status, date, time)
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		fmt.Println(status)
+		for _, timeout := range timeouts {
+			fmt.Printf("%s timeout fired after %v at %s %s\n", timeout.Kind, timeout.Duration, timeout.Date, timeout.Time)
+		}
 	},
 }
 
@@ -78,22 +115,38 @@ var MsgsCmd = &cobra.Command{
 	X = received msg
 	O = sent msg
 
-  Takes five args: duration in miliseconds (up to 59999), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time.`,
+	With --kinds, each interval instead gets one row per msg kind and channel (chId) it arrived on, counting how many msgs of that kind were received from each node on that channel (_ = none). Unknown kinds are rejected.
+	Kinds: NewRoundStep, CommitStep, Proposal, ProposalPOL, BlockPart, Vote, HasVote, VoteSetMaj23, VoteSetBits, Tx (mempool), or all
+
+	Intervals line up with the clock (eg. 10m intervals start at 04:30, 04:40...), and intervals in which nothing was received are still displayed.
+
+	With --follow, msgs tails a log that's still being written (raw or rendered, surviving rotation and truncation), printing each row as its interval closes and redrawing the row for the current interval as msgs arrive.
+
+  Takes five args: interval length (500ms, 5s, 10m, 1h; a bare number is read as miliseconds), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time; or only the interval length with --follow.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) != 5 {
-			log.Fatal("5 args required: duration in miliseconds (up to 59999), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time")
-		}
+		if *msgsFollow {
+			if len(args) != 1 {
+				log.Fatal("1 arg required with --follow: interval length (500ms, 5s, 10m, 1h)")
+			}
 
-		file, err := filefuncs.OpenLog(logName)
-		if err != nil {
-			log.Fatal(err)
+			dur, err := parseInterval(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if dur <= 0 {
+				log.Fatal("interval must be positive")
+			}
+
+			followMsgs(dur)
+			return
 		}
 
-		entries, err := filefuncs.UnmarshalLines(file)
-		if err != nil {
-			log.Fatal(err)
+		if len(args) != 5 {
+			log.Fatal("5 args required: interval length (500ms, 5s, 10m, 1h), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time")
 		}
-		dur, err := strconv.Atoi(args[0])
+
+		dur, err := parseInterval(args[0])
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -103,11 +156,21 @@ var MsgsCmd = &cobra.Command{
 		enD := args[3]
 		enT := args[4]
 
+		entries, err := loadRange(stD, stT, enD, enT, false)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		nodes, err := filefuncs.UnmarshalNodes()
 		if err != nil {
 			log.Fatal(err)
 		}
 
+		if len(*kinds) > 0 {
+			printKinds(entries, nodes, dur, stD, stT, enD, enT)
+			return
+		}
+
 		_, err = reader.GetMessages(entries, nodes, dur, stD, stT, enD, enT, *commits)
 		if err != nil {
 			log.Fatal(err)
@@ -144,3 +207,138 @@ var NodesCmd = &cobra.Command{
 		filefuncs.GetNodes(names)
 	},
 }
+
+//parseInterval reads an interval length as a Go duration (eg. 5s); bare numbers are miliseconds, as msgs used to take
+func parseInterval(arg string) (time.Duration, error) {
+	if ms, err := strconv.Atoi(arg); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(arg)
+}
+
+//compareSnapshot prints the state rebuilt from the log next to a /dump_consensus_state snapshot's, and where they differ
+func compareSnapshot(args []string) {
+	if len(args) != 2 && (len(args) != 0 || *utcOffset == "") {
+		log.Fatal("2 args required with --snapshot, unless --utc-offset is given: date (01-01), and time (00:00:00[.000 if you'd like more specificity])")
+	}
+
+	nodes, err := filefuncs.UnmarshalNodes()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(*snapshot)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	snap, err := reader.ParseSnapshot(data, nodes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var date, clock string
+	if len(args) == 2 {
+		date, clock = args[0], args[1]
+	} else {
+		offset, err := time.ParseDuration(*utcOffset)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		date, clock = snap.At(offset)
+		if date == "" {
+			log.Fatal("the snapshot has no times in it; give the date and time it was taken")
+		}
+	}
+
+	at, err := reader.ParseTime(date, clock)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := loadRange(date, clock, date, clock, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	//the state as of the snapshot, without anything logged after it
+	var before []reader.LogEntry
+	for _, entry := range entries {
+		entryT, err := reader.EntryTime(entry)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if entryT.After(at) {
+			break
+		}
+		before = append(before, entry)
+	}
+
+	status, err := reader.GetStatus(before, nodes, date, clock)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("log at %s %s:\n%v\n", date, clock, status)
+	fmt.Printf("snapshot:\n%v\n", snap.Status)
+
+	diffs := reader.DiffStatus(status, snap.Status, nodes)
+	if len(diffs) == 0 {
+		fmt.Println("no differences")
+	}
+	for _, diff := range diffs {
+		fmt.Printf("  %s: log %s, snapshot %s\n", diff.Field, diff.Logs, diff.Snapshot)
+	}
+
+	for _, peer := range snap.Peers {
+		fmt.Printf("peer %s: %d/%d/%s proposal=%s parts=%s prevotes=%s precommits=%s\n", peer.Name, peer.Height, peer.Round, peer.Step, yesNo(peer.Proposal), peer.BlockParts, peer.Prevotes, peer.Precommits)
+	}
+}
+
+//prints one row per selected msg kind for each interval
+func printKinds(entries []reader.LogEntry, nodes []reader.Node, dur time.Duration, stD string, stT string, enD string, enT string) {
+	selected := *kinds
+	if len(selected) == 1 && selected[0] == "all" {
+		selected = reader.MsgKinds
+	}
+
+	countArr, err := reader.GetMessageKinds(entries, nodes, dur, stD, stT, enD, enT, selected)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, counts := range countArr {
+		for _, kind := range selected {
+			var channels []string
+			for ch := range counts.Channels[kind] {
+				channels = append(channels, ch)
+			}
+
+			//a kind nothing was received of still gets its row
+			if len(channels) == 0 {
+				fmt.Printf("%s %-24s %v\n", counts.Time, kind, countCells(counts.Counts[kind]))
+				continue
+			}
+
+			sort.Strings(channels)
+			for _, ch := range channels {
+				fmt.Printf("%s %-24s %v\n", counts.Time, kind+" (ch "+ch+")", countCells(counts.Channels[kind][ch]))
+			}
+		}
+	}
+}
+
+//countCells marks each node's count, or _ for none
+func countCells(counts []int) []string {
+	var cells []string
+
+	for _, count := range counts {
+		if count == 0 {
+			cells = append(cells, "_")
+		} else {
+			cells = append(cells, strconv.Itoa(count))
+		}
+	}
+	return cells
+}
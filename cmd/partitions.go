@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var partitionsAt *string
+
+func init() {
+	RootCmd.AddCommand(PartitionsCmd)
+
+	partitionsAt = PartitionsCmd.PersistentFlags().String("at", "", "print the P2P graph at this date and time (01-01 00:00:00[.000]) instead")
+
+	viper.BindPFlag("partitions.at", PartitionsCmd.PersistentFlags().Lookup("at"))
+}
+
+var PartitionsCmd = &cobra.Command{
+	Use:   "partitions",
+	Short: "Find when the validators split into groups that couldn't reach each other",
+	Long: `partitions puts together the cluster's P2P graph from every node's log (peers being added and stopped, and messages received from peers connected before a log began) and reports each period when the validators were split into components with no path between them, with its start and end and the members of each side.
+
+	Where both nodes of a connection have logs, it's counted as up only while both say so. Partitions are counted from when the cluster first comes together.
+	With --at, the connections and components at that moment are printed instead.
+
+  Takes any number of args: rendered log files (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logs, err := loadCluster(args, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		topology, err := reader.GetTopology(logs, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if *partitionsAt != "" {
+			date, clock, err := splitTime(*partitionsAt)
+			if err != nil {
+				log.Fatal(err)
+			}
+			at, err := reader.ParseTime(date, clock)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, edge := range topology.Edges(at) {
+				fmt.Println(edge[0], "--", edge[1])
+			}
+			fmt.Println("components:", joinComponents(topology.Components(at)))
+			return
+		}
+
+		formed, ok := topology.Formed()
+		if !ok {
+			fmt.Println("the validators never formed a single connected group")
+			return
+		}
+		fmt.Println("connected at", formed.Format("01-02 15:04:05.000"))
+
+		partitions := topology.Partitions()
+		if len(partitions) == 0 {
+			fmt.Println("no partitions")
+		}
+
+		for _, partition := range partitions {
+			end := "end of logs"
+			if !partition.End.IsZero() {
+				end = fmt.Sprintf("%s (%v)", partition.End.Format("01-02 15:04:05.000"), partition.End.Sub(partition.Start))
+			}
+			fmt.Printf("%s to %s: %s\n", partition.Start.Format("01-02 15:04:05.000"), end, joinComponents(partition.Components))
+		}
+	},
+}
+
+//loadCluster loads the log of every node in the cluster (see clusterLogs)
+func loadCluster(args []string, nodes []reader.Node) ([][]reader.LogEntry, error) {
+	var logs [][]reader.LogEntry
+
+	for _, name := range clusterLogs(args, nodes) {
+		entries, err := loadEntries(name)
+		if err != nil {
+			return logs, err
+		}
+		logs = append(logs, entries)
+	}
+	return logs, nil
+}
+
+//joinComponents writes components as "node0 node1 | node2"
+func joinComponents(components [][]string) string {
+	var sides []string
+	for _, component := range components {
+		sides = append(sides, strings.Join(component, " "))
+	}
+	return strings.Join(sides, " | ")
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var minValidators *int
+var flapCount *int
+var flapWindow *string
+
+func init() {
+	RootCmd.AddCommand(PeersCmd)
+
+	minValidators = PeersCmd.PersistentFlags().Int("min-validators", 0, "flag periods connected to fewer validators than this (default: enough for +2/3 with the node itself)")
+	flapCount = PeersCmd.PersistentFlags().Int("flap-count", 3, "flag peers disconnected this many times within --flap-window")
+	flapWindow = PeersCmd.PersistentFlags().String("flap-window", "1m", "window for --flap-count (eg. 30s, 5m)")
+
+	viper.BindPFlag("peers.min-validators", PeersCmd.PersistentFlags().Lookup("min-validators"))
+	viper.BindPFlag("peers.flap-count", PeersCmd.PersistentFlags().Lookup("flap-count"))
+	viper.BindPFlag("peers.flap-window", PeersCmd.PersistentFlags().Lookup("flap-window"))
+}
+
+var PeersCmd = &cobra.Command{
+	Use:   "peers",
+	Short: "Rebuild each node's peer set over time",
+	Long: `peers lists every connection each node made or accepted, from when the peer was added until it was stopped, along with failed dials, flapping peers, and periods when the node was connected to too few validators.
+
+	in/out       the peer dialed us, or we dialed it
+	persistent   yes if the node reconnects to the peer when it drops
+	validator    yes if the peer is in nodes.json
+	(reason)     the error the peer was stopped for, or "stopped" if none was given
+
+	A peer is flapping if it's disconnected --flap-count times within --flap-window. Periods under --min-validators are counted from the node's first peer event.
+
+  Takes any number of args: rendered log files (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		window, err := parseInterval(*flapWindow)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		min := *minValidators
+		if min == 0 {
+			min = len(nodes) * 2 / 3
+		}
+
+		for _, name := range clusterLogs(args, nodes) {
+			entries, err := loadEntries(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			peers, err := reader.GetPeers(entries, nodes, min, *flapCount, window)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Println("==", name)
+
+			for _, conn := range peers.Conns {
+				end := "still connected"
+				if conn.EndDate != "" {
+					end = conn.EndDate + " " + conn.EndTime + " (" + conn.Reason + ")"
+				}
+				fmt.Printf("%s %s %s persistent=%s validator=%s: %s %s to %s\n", conn.Name, conn.Ip, conn.Direction, yesNo(conn.Persistent), yesNo(conn.Validator), conn.StartDate, conn.StartTime, end)
+			}
+
+			for _, failure := range peers.Failures {
+				fmt.Printf("%s %s %s %s %s: %s\n", failure.Date, failure.Time, failure.Event, failure.Name, failure.Ip, failure.Err)
+			}
+
+			for _, flap := range peers.Flapping {
+				fmt.Printf("flapping: %s %s, %d disconnects, from %s %s\n", flap.Name, flap.Ip, flap.Disconnects, flap.Date, flap.Time)
+			}
+
+			for _, gap := range peers.Gaps {
+				end := "end of log"
+				if gap.EndDate != "" {
+					end = gap.EndDate + " " + gap.EndTime
+				}
+				fmt.Printf("under %d validators: %s %s to %s (as few as %d)\n", min, gap.StartDate, gap.StartTime, end, gap.Connected)
+			}
+			fmt.Println()
+		}
+	},
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
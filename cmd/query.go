@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var from *string
+var to *string
+var asJSON *bool
+
+func init() {
+	RootCmd.AddCommand(QueryCmd)
+
+	from = QueryCmd.PersistentFlags().String("from", "", "only entries from this date and time on (01-01 00:00:00[.000])")
+	to = QueryCmd.PersistentFlags().String("to", "", "only entries through this date and time (01-01 00:00:00[.000])")
+	asJSON = QueryCmd.PersistentFlags().Bool("json", false, "print each entry as a JSON object rather than a log line")
+
+	viper.BindPFlag("query.from", QueryCmd.PersistentFlags().Lookup("from"))
+	viper.BindPFlag("query.to", QueryCmd.PersistentFlags().Lookup("to"))
+	viper.BindPFlag("query.json", QueryCmd.PersistentFlags().Lookup("json"))
+}
+
+var QueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Print the entries of a node's log that match an expression",
+	Long: `query is grep for t-logs: it prints each entry that matches an expression, as a log line or (with --json) a JSON object.
+
+	eg. 'module=consensus AND height>=100 AND descrip~"enterPrevote" AND peer=node3'
+
+	Comparisons are field op value, joined with AND, OR, NOT and parentheses; quote values with spaces or symbols in them.
+	Fields: level, date, time, descrip, module, peer (the node an entry was received from, or is about), or any other key in the entry (msg, src, height...)
+	Ops: = != < <= > >= ~ (matches regex) !~ (doesn't)
+
+	height, round, chId, newHeight, oldHeight and numTxs compare as numbers; height and round also read steps (eg. enterPrevote(100/0)).
+	A value naming a node in nodes.json also matches that node's IP, pubkey or index, so peer=node3 and proposer=node3 both work.
+
+  Takes one arg: the expression.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			log.Fatal("1 arg required: the expression (eg. 'module=consensus AND peer=node3')")
+		}
+
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		query, err := reader.ParseQuery(strings.Join(args, " "), nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var stD, stT, enD, enT string
+
+		if *from != "" {
+			stD, stT, err = splitTime(*from)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if *to != "" {
+			enD, enT, err = splitTime(*to)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		var entries []reader.LogEntry
+		if stD != "" && enD != "" {
+			entries, err = loadRange(stD, stT, enD, enT, false)
+		} else {
+			entries, err = loadEntries(logName)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		matches, err := reader.QueryEntries(entries, query, stD, stT, enD, enT)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+
+		for _, entry := range matches {
+			if *asJSON {
+				encoder.Encode(queryEntry{entry.Level, entry.Date, entry.Time, entry.Descrip, entry.Module, entry.Other})
+			} else {
+				fmt.Println(filefuncs.MarshalLine(entry))
+			}
+		}
+	},
+}
+
+type queryEntry struct {
+	Level   string            `json:"level"`
+	Date    string            `json:"date"`
+	Time    string            `json:"time"`
+	Descrip string            `json:"descrip"`
+	Module  string            `json:"module"`
+	Other   map[string]string `json:"other"`
+}
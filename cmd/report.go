@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var out *string
+var reportInterval *string
+
+func init() {
+	RootCmd.AddCommand(ReportCmd)
+
+	out = ReportCmd.PersistentFlags().String("out", "report.html", "file to write the report to")
+	reportInterval = ReportCmd.PersistentFlags().String("interval", "", "heatmap interval length (eg. 1s); by default the log is split into about 200 intervals")
+
+	viper.BindPFlag("report.out", ReportCmd.PersistentFlags().Lookup("out"))
+	viper.BindPFlag("report.interval", ReportCmd.PersistentFlags().Lookup("interval"))
+}
+
+var ReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a self-contained HTML report of a node's log",
+	Long: `For a given node, report writes a single HTML file, with no external assets, holding a summary of every height (duration, rounds, proposer), a heatmap of msgs received from each peer over the whole log, and charts of when each validator's votes arrived in every round.
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(entries) == 0 {
+			log.Fatal("log is empty")
+		}
+
+		data, err := buildReport(entries, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		err = reportTemplate.Execute(file, data)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("wrote", *out)
+	},
+}
+
+//report chart dimensions, in pixels
+const (
+	chartWidth  = 600
+	chartMargin = 40
+	chartRow    = 16
+)
+
+type reportData struct {
+	Log        string
+	From       string
+	To         string
+	Interval   time.Duration
+	Heights    []reader.Height
+	Nodes      []reader.Node
+	TimeStamps []string
+	Heatmap    [][]string
+	Charts     []reportChart
+}
+
+//reportChart plots vote arrivals for one round: a row per validator, prevotes as circles and precommits as squares
+type reportChart struct {
+	Title  string
+	Height int
+	MaxMs  int64
+	Marks  []reportMark
+}
+
+//reportMark is placed by its center, or top left corner if it's a square
+type reportMark struct {
+	X      int
+	Y      int
+	Square bool
+	Color  string
+	Title  string
+}
+
+//buildReport gathers everything the report displays
+func buildReport(entries []reader.LogEntry, nodes []reader.Node) (reportData, error) {
+	first := entries[0]
+	last := entries[len(entries)-1]
+
+	data := reportData{Log: logName, From: first.Date + " " + first.Time, To: last.Date + " " + last.Time, Nodes: nodes}
+
+	heights, err := reader.GetHeights(entries, nodes)
+	if err != nil {
+		return data, err
+	}
+	data.Heights = heights
+
+	data.Interval, err = reportSpan(first, last)
+	if err != nil {
+		return data, err
+	}
+
+	timeStamps, msgArr, err := reader.MessageRows(entries, nodes, data.Interval, first.Date, first.Time, last.Date, last.Time)
+	if err != nil {
+		return data, err
+	}
+	data.TimeStamps = timeStamps
+
+	//the heatmap reads a row per peer, labelled from nodes, so msgs rows are turned on their side in the same order
+	data.Heatmap, err = reader.PeerRows(msgArr, nodes)
+	if err != nil {
+		return data, err
+	}
+
+	arrivals, err := reader.GetVoteArrivals(entries, nodes)
+	if err != nil {
+		return data, err
+	}
+	data.Charts = voteCharts(arrivals, len(nodes))
+
+	return data, err
+}
+
+//reportSpan returns the --interval given, or else one that splits the log into about 200 intervals
+func reportSpan(first reader.LogEntry, last reader.LogEntry) (time.Duration, error) {
+	if *reportInterval != "" {
+		return parseInterval(*reportInterval)
+	}
+
+	start, err := reader.EntryTime(first)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := reader.EntryTime(last)
+	if err != nil {
+		return 0, err
+	}
+
+	spans := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+		time.Minute, 5 * time.Minute, 10 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+	for _, span := range spans {
+		if end.Sub(start)/span <= 200 {
+			return span, nil
+		}
+	}
+	return 24 * time.Hour, nil
+}
+
+//voteCharts lays out one chart per round, scaled to the latest vote in that round
+func voteCharts(arrivals []reader.VoteArrival, lenNodes int) []reportChart {
+	var charts []reportChart
+	var rounds = make(map[string]int)
+
+	for _, arrival := range arrivals {
+		key := fmt.Sprintf("%d/%d", arrival.Height, arrival.Round)
+
+		i, ok := rounds[key]
+		if !ok {
+			i = len(charts)
+			rounds[key] = i
+			charts = append(charts, reportChart{Title: "height " + key, Height: (lenNodes + 2) * chartRow})
+		}
+
+		if ms := arrival.Offset.Nanoseconds() / int64(time.Millisecond); ms > charts[i].MaxMs {
+			charts[i].MaxMs = ms
+		}
+	}
+
+	for _, arrival := range arrivals {
+		chart := &charts[rounds[fmt.Sprintf("%d/%d", arrival.Height, arrival.Round)]]
+
+		x := chartMargin
+		if chart.MaxMs > 0 {
+			x = chartMargin + int(arrival.Offset.Nanoseconds()/int64(time.Millisecond)*int64(chartWidth-2*chartMargin)/chart.MaxMs)
+		}
+
+		color := "#2e7d32"
+		if arrival.Nil {
+			color = "#c62828"
+		}
+
+		mark := reportMark{
+			X:      x,
+			Y:      (arrival.Index+1)*chartRow + chartRow/2,
+			Square: arrival.Type == "Precommit",
+			Color:  color,
+			Title:  fmt.Sprintf("%s %s +%s", arrival.Name, arrival.Type, arrival.Offset),
+		}
+
+		//squares are placed by their corner rather than their center
+		if mark.Square {
+			mark.X, mark.Y = mark.X-4, mark.Y-4
+		}
+
+		chart.Marks = append(chart.Marks, mark)
+	}
+	return charts
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>t-logs report: {{.Log}}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 20px; }
+table { border-collapse: collapse; }
+th, td { padding: 2px 8px; text-align: left; }
+.heights tr:nth-child(even) { background: #f5f5f5; }
+.heights .rounds { background: #fff3e0; }
+.heatmap td { padding: 0; width: 6px; height: 14px; border: 1px solid #fff; }
+.heatmap th { font-weight: normal; white-space: nowrap; }
+.X { background: #2e7d32; }
+.O { background: #1565c0; }
+._ { background: #eee; }
+svg { display: block; margin-bottom: 12px; }
+</style>
+</head>
+<body>
+<h1>{{.Log}}</h1>
+<p>{{.From}} to {{.To}}</p>
+
+<h2>Heights</h2>
+<table class="heights">
+<tr><th>Height</th><th>Date</th><th>Start</th><th>Commit</th><th>Duration</th><th>Rounds</th><th>Proposer</th></tr>
+{{range .Heights}}<tr{{if gt .Rounds 1}} class="rounds"{{end}}><td>{{.Height}}</td><td>{{.Date}}</td><td>{{.Start}}</td><td>{{.Commit}}</td><td>{{.Duration}}</td><td>{{.Rounds}}</td><td>{{.Proposer}}</td></tr>
+{{end}}</table>
+
+<h2>Msgs</h2>
+<p>One column per {{.Interval}}. Green = received msg, blue = sent msg, grey = nothing.</p>
+<table class="heatmap">
+{{$stamps := .TimeStamps}}{{range $i, $row := .Heatmap}}<tr><th>{{(index $.Nodes $i).Index}} {{(index $.Nodes $i).Name}}</th>{{range $j, $cell := $row}}<td class="{{$cell}}" title="{{index $stamps $j}}"></td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Vote arrivals</h2>
+<p>Time since the node entered each round. One row per validator index; circles are prevotes, squares are precommits, red votes are nil.</p>
+{{range .Charts}}<svg width="600" height="{{.Height}}" xmlns="http://www.w3.org/2000/svg">
+<text x="0" y="12">{{.Title}} (0 to {{.MaxMs}}ms)</text>
+{{range .Marks}}{{if .Square}}<rect x="{{.X}}" y="{{.Y}}" width="8" height="8" fill="{{.Color}}"><title>{{.Title}}</title></rect>{{else}}<circle cx="{{.X}}" cy="{{.Y}}" r="4" fill="{{.Color}}"><title>{{.Title}}</title></circle>{{end}}
+{{end}}</svg>
+{{end}}
+</body>
+</html>
+`))
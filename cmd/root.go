@@ -1,18 +1,75 @@
 package cmd
 
 import (
+	"bufio"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var logName string
+var useIndex bool
 
 func init() {
 	RootCmd.PersistentFlags().StringVar(&logName, "log", "", "name (file path) of the log file")
+	RootCmd.PersistentFlags().BoolVar(&useIndex, "index", true, "let state and msgs seek with a sidecar index of the log (log.idx, built on first use)")
 	viper.BindPFlag("logName", RootCmd.PersistentFlags().Lookup("logname"))
+	viper.BindPFlag("index", RootCmd.PersistentFlags().Lookup("index"))
 }
 
 var RootCmd = &cobra.Command{
 	Use:   "t-logs",
 	Short: "T-logs is a Tendermint debugging tool",
 }
+
+//loadLog reads the --log file and nodes.json, which every analysis command needs
+func loadLog() ([]reader.LogEntry, []reader.Node, error) {
+	entries, err := loadEntries(logName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := filefuncs.UnmarshalNodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, nodes, err
+}
+
+//loadEntries reads the given rendered log file, or consensus WAL (eg. cs.wal)
+func loadEntries(name string) ([]reader.LogEntry, error) {
+	file, err := filefuncs.OpenLog(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if filefuncs.IsWAL(name) {
+		//without nodes.json, the WAL's peers are just keys
+		nodes, _ := filefuncs.UnmarshalNodes()
+		return filefuncs.ReadWAL(bufio.NewReader(file), nodes)
+	}
+
+	return filefuncs.UnmarshalLines(file)
+}
+
+//loadRange reads the --log file from stD stT through enD enT, seeking with the log's index (unless --index=false).
+//With wholeHeights, reading starts where the height underway at the start began, so its state can be rebuilt
+func loadRange(stD string, stT string, enD string, enT string, wholeHeights bool) ([]reader.LogEntry, error) {
+	if !useIndex || filefuncs.IsWAL(logName) {
+		return loadEntries(logName)
+	}
+
+	index, err := filefuncs.LoadIndex(logName)
+	if err != nil {
+		log.Println("reading the whole log, as it can't be indexed:", err)
+		return loadEntries(logName)
+	}
+
+	return filefuncs.ReadIndexed(logName, index, stD, stT, enD, enT, wholeHeights)
+}
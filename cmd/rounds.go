@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(RoundsCmd)
+}
+
+var RoundsCmd = &cobra.Command{
+	Use:   "rounds",
+	Short: "Explain why heights went past round 0",
+	Long: `For a given node, rounds lists every round change within a height along with its most likely cause: an invalid proposal, no proposal received in time, no +2/3 prevote, or no +2/3 precommit. Validators whose votes never arrived are named, and the node's state at the end of the failed round is displayed.
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		changes, err := reader.ExplainRounds(entries, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, change := range changes {
+			fmt.Printf("%s %s height %d round %d -> %d: %s\n", change.Date, change.Time, change.Height, change.FromRound, change.ToRound, change.Cause)
+			if len(change.Missing) > 0 {
+				fmt.Println("missing:", strings.Join(change.Missing, ", "))
+			}
+			fmt.Println(change.Status)
+		}
+	},
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var addr *string
+
+//maxServeRows caps the rows a single /msgs response may hold
+const maxServeRows = 10000
+
+func init() {
+	RootCmd.AddCommand(ServeCmd)
+
+	addr = ServeCmd.PersistentFlags().String("addr", ":8080", "address to listen on")
+
+	viper.BindPFlag("serve.addr", ServeCmd.PersistentFlags().Lookup("addr"))
+}
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve state and msgs for a cluster's logs over HTTP",
+	Long: `serve loads nodes.json and each node's rendered log once, then answers JSON requests:
+
+	/nodes                                      nodes.json
+	/status?node=&time=                         state, as given by the state command, at a date and time (01-01 00:00:00[.000])
+	/status?node=&height=                       state once the node is done with a height (just before the next one begins)
+	/msgs?node=&from=&to=&interval=             msgs rows, as given by the msgs command (interval: 500ms, 5s, 10m, 1h)
+
+	node may be a node's name or index. Each response is a JSON object; errors are returned as {"error": "..."}. A time before anything was logged is not found, and /msgs refuses to answer with more than 10000 rows.
+
+  Takes any number of args: rendered log files to serve (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		srv := &server{nodes: nodes, replays: make(map[string]*reader.Replay)}
+
+		for _, name := range clusterLogs(args, nodes) {
+			entries, err := loadEntries(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			srv.replays[name], err = reader.NewReplay(entries, nodes)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		http.HandleFunc("/nodes", srv.handleNodes)
+		http.HandleFunc("/status", srv.handleStatus)
+		http.HandleFunc("/msgs", srv.handleMsgs)
+
+		fmt.Println("serving", len(srv.replays), "logs on", *addr)
+		log.Fatal(http.ListenAndServe(*addr, nil))
+	},
+}
+
+//server holds every log, replayed once at startup, keyed by file name
+type server struct {
+	nodes   []reader.Node
+	replays map[string]*reader.Replay
+}
+
+type msgsRow struct {
+	Time string   `json:"time"`
+	Msgs []string `json:"msgs"`
+}
+
+func (srv *server) handleNodes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, srv.nodes)
+}
+
+func (srv *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	replay, err := srv.replay(r.FormValue("node"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	var index int
+
+	if height := r.FormValue("height"); height != "" {
+		h, err := strconv.Atoi(height)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad height %q", height))
+			return
+		}
+
+		index = replay.EndOfHeight(h)
+		if index < 0 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("height %d not in log", h))
+			return
+		}
+	} else {
+		date, clock, err := splitTime(r.FormValue("time"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		index, err = replay.FindTime(date, clock)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if index < 0 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("nothing logged by %s %s", date, clock))
+			return
+		}
+	}
+
+	status, err := replay.StatusAt(index)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (srv *server) handleMsgs(w http.ResponseWriter, r *http.Request) {
+	replay, err := srv.replay(r.FormValue("node"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	stD, stT, err := splitTime(r.FormValue("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	enD, enT, err := splitTime(r.FormValue("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	dur, err := parseInterval(r.FormValue("interval"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = checkRows(dur, stD, stT, enD, enT)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	timeStamps, msgArr, err := reader.MessageRows(replay.Entries, srv.nodes, dur, stD, stT, enD, enT)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows := []msgsRow{}
+	for i, msgs := range msgArr {
+		rows = append(rows, msgsRow{timeStamps[i], msgs})
+	}
+
+	writeJSON(w, http.StatusOK, rows)
+}
+
+//checkRows refuses a msgs request that would answer with more than maxServeRows rows
+func checkRows(dur time.Duration, stD string, stT string, enD string, enT string) error {
+	if dur <= 0 {
+		return fmt.Errorf("interval must be positive, received %s", dur)
+	}
+
+	from, err := reader.ParseTime(stD, stT)
+	if err != nil {
+		return err
+	}
+
+	to, err := reader.ParseTime(enD, enT)
+	if err != nil {
+		return err
+	}
+
+	//logs carry no year, so a range ending before it starts runs into the next one
+	if to.Before(from) {
+		to = to.AddDate(1, 0, 0)
+	}
+
+	if to.Sub(from)/dur >= maxServeRows {
+		return fmt.Errorf("%s intervals from %s %s to %s %s would make more than %d rows; use a longer interval", dur, stD, stT, enD, enT, maxServeRows)
+	}
+	return nil
+}
+
+//replay finds a node's log by its file name, or by the name or index of a node in nodes.json
+func (srv *server) replay(node string) (*reader.Replay, error) {
+	if replay, ok := srv.replays[node]; ok {
+		return replay, nil
+	}
+
+	for _, n := range srv.nodes {
+		if n.Index == node || n.Name == node {
+			if replay, ok := srv.replays[n.Name]; ok {
+				return replay, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no log for node %q", node)
+}
+
+//clusterLogs returns the logs given as args, or else every log named in nodes.json
+func clusterLogs(args []string, nodes []reader.Node) []string {
+	if len(args) > 0 {
+		return args
+	}
+
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+//splitTime splits "01-01 00:00:00[.000]" into a date and a time
+func splitTime(value string) (string, string, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("bad time %q: want date and time (01-01 00:00:00[.000])", value)
+	}
+	return fields[0], fields[1], nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(SyncCmd)
+}
+
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Follow a node's fast sync until it switched to consensus",
+	Long: `For a given node, sync reports how its fast sync went: the rate in blocks/sec over time (as the node logged it), the peers it synced from with the heights they reported and the blocks they sent, requests that timed out, peers the blockchain reactor dropped (for timing out or sending a bad block), and when it caught up and switched to consensus.
+
+	rate lines     height/highest peer height, blocks/sec
+	peer lines     reported height, blocks sent, timeouts, and (dropped) if the blockchain reactor stopped it
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		sync, ok, err := reader.GetSync(entries, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !ok {
+			fmt.Println("no fast sync in this log")
+			return
+		}
+
+		fmt.Println("fast sync started", sync.StartDate, sync.StartTime)
+
+		for _, rate := range sync.Rates {
+			fmt.Printf("%s %s %d/%d %.2f blocks/s\n", rate.Date, rate.Time, rate.Height, rate.MaxPeerHeight, rate.Rate)
+		}
+
+		for _, peer := range sync.Peers {
+			dropped := ""
+			if peer.Banned {
+				dropped = " (dropped)"
+			}
+			fmt.Printf("peer %s: height %d, %d blocks, %d timeouts%s\n", peer.Name, peer.Height, peer.Blocks, peer.Timeouts, dropped)
+		}
+
+		for _, timeout := range sync.Timeouts {
+			fmt.Printf("%s %s timeout %s: %s\n", timeout.Date, timeout.Time, timeout.Name, timeout.Err)
+		}
+
+		for _, ban := range sync.Bans {
+			fmt.Printf("%s %s dropped %s: %s\n", ban.Date, ban.Time, ban.Name, ban.Err)
+		}
+
+		if sync.EndDate == "" {
+			fmt.Println("still syncing at the end of the log")
+		} else {
+			fmt.Printf("switched to consensus %s %s at height %d, after %v\n", sync.EndDate, sync.EndTime, sync.Height, sync.Duration)
+		}
+	},
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var timeoutConfig *string
+
+func init() {
+	RootCmd.AddCommand(TimeoutsCmd)
+
+	timeoutConfig = TimeoutsCmd.PersistentFlags().String("config", "", "the node's config.toml, to compare its timeouts with the ones it used")
+
+	viper.BindPFlag("timeouts.config", TimeoutsCmd.PersistentFlags().Lookup("config"))
+}
+
+var TimeoutsCmd = &cobra.Command{
+	Use:   "timeouts",
+	Short: "List the consensus timeouts a node scheduled and which of them fired",
+	Long: `For a given node, timeouts lists every consensus timeout it scheduled, by height and round, with how long it was set for and whether it fired before the node moved on, then sums them up by kind.
+
+	propose      waiting for a proposal (timeout_propose)
+	prevote      waiting for more prevotes after seeing +2/3 of any (timeout_prevote)
+	precommit    waiting for more precommits after seeing +2/3 of any (timeout_precommit)
+	commit       waiting after a commit before starting the next height (timeout_commit)
+
+	With --config, each timeout is compared with the node's config.toml: the kind's timeout, plus its delta (eg. timeout_propose_delta) for every round after the first. Timeouts that don't match are marked MISCONFIGURED, except the commit timeout, which only runs for what's left of timeout_commit once the block is committed, and so is only marked if it's longer. Values may be given in milliseconds (3000) or as durations (3s).
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, _, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var config reader.TimeoutConfig
+		if *timeoutConfig != "" {
+			config, err = readTimeoutConfig(*timeoutConfig)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		timeouts, err := reader.GetTimeouts(entries)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		scheduled := make(map[string]int)
+		fired := make(map[string]int)
+		longest := make(map[string]time.Duration)
+		misconfigured := make(map[string]int)
+
+		for _, timeout := range timeouts {
+			state := "fired"
+			if !timeout.Fired {
+				state = "scheduled, not fired"
+			}
+
+			mark := ""
+			if expected, wrong := config.Misconfigured(timeout); wrong {
+				mark = fmt.Sprintf(" MISCONFIGURED (config: %v)", expected)
+				misconfigured[timeout.Kind]++
+			}
+
+			fmt.Printf("%s %s %d/%d %s %v %s%s\n", timeout.Date, timeout.Time, timeout.Height, timeout.Round, timeout.Kind, timeout.Duration, state, mark)
+
+			scheduled[timeout.Kind]++
+			if timeout.Fired {
+				fired[timeout.Kind]++
+			}
+			if timeout.Duration > longest[timeout.Kind] {
+				longest[timeout.Kind] = timeout.Duration
+			}
+		}
+
+		for _, kind := range reader.TimeoutKinds {
+			configured := ""
+			if expected, ok := config.Expected(kind, 0); ok {
+				configured = fmt.Sprintf(", config %v (%d misconfigured)", expected, misconfigured[kind])
+			}
+			fmt.Printf("%s: %d fired of %d scheduled, longest %v%s\n", kind, fired[kind], scheduled[kind], longest[kind], configured)
+		}
+	},
+}
+
+//readTimeoutConfig reads the consensus timeouts from a config.toml, in its [consensus] section or at the top level as
+//older versions had them. Bare numbers are milliseconds
+func readTimeoutConfig(name string) (reader.TimeoutConfig, error) {
+	config := make(reader.TimeoutConfig)
+
+	v := viper.New()
+	v.SetConfigFile(name)
+	v.SetConfigType("toml")
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return config, err
+	}
+
+	for _, kind := range reader.TimeoutKinds {
+		for _, key := range []string{"timeout_" + kind, "timeout_" + kind + "_delta"} {
+			value := v.GetString("consensus." + key)
+			if value == "" {
+				value = v.GetString(key)
+			}
+			if value == "" {
+				continue
+			}
+
+			if ms, err := strconv.Atoi(value); err == nil {
+				config[key] = time.Duration(ms) * time.Millisecond
+				continue
+			}
+
+			dur, err := time.ParseDuration(value)
+			if err != nil {
+				return config, fmt.Errorf("bad %s in %s: %v", key, name, err)
+			}
+			config[key] = dur
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var traceFormat *string
+var traceOut *string
+var traceYear *int
+
+func init() {
+	RootCmd.AddCommand(TraceCmd)
+
+	traceFormat = TraceCmd.PersistentFlags().String("format", "chrome", "chrome (trace-event JSON, for Perfetto or chrome://tracing) or otlp (OTLP JSON, for Jaeger and other OpenTelemetry tools)")
+	traceOut = TraceCmd.PersistentFlags().String("out", "trace.json", "file to write the trace to")
+	traceYear = TraceCmd.PersistentFlags().Int("year", time.Now().Year(), "year the logs were written in (logs leave it out, and OTLP needs absolute times)")
+
+	viper.BindPFlag("trace.format", TraceCmd.PersistentFlags().Lookup("format"))
+	viper.BindPFlag("trace.out", TraceCmd.PersistentFlags().Lookup("out"))
+	viper.BindPFlag("trace.year", TraceCmd.PersistentFlags().Lookup("year"))
+}
+
+var TraceCmd = &cobra.Command{
+	Use:   "trace",
+	Short: "Export heights, rounds and steps as trace spans",
+	Long: `trace splits each node's log into spans, a height holding its rounds and a round holding its steps (NewRound, Propose, Prevote, Precommit, Commit), and writes them to a file with one track per validator. Votes and block parts received, and votes sent, are events on the step they arrived in.
+
+	--format chrome writes trace-event JSON, for Perfetto (ui.perfetto.dev) or chrome://tracing; times start from the earliest log.
+	--format otlp writes OTLP JSON, for Jaeger and other OpenTelemetry tools, with one trace per height across every validator.
+
+  Takes any number of args: rendered log files to trace (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var tracks []traceTrack
+
+		//logs of nodes missing from nodes.json get ids after every validator's, so no two tracks share one
+		nextId := 0
+		for _, node := range nodes {
+			index, err := strconv.Atoi(node.Index)
+			if err == nil && index >= nextId {
+				nextId = index + 1
+			}
+		}
+
+		for _, name := range clusterLogs(args, nodes) {
+			entries, err := loadEntries(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			spans, err := reader.GetSpans(entries, nodes)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			track := traceTrack{Name: name, Id: -1, Spans: spans}
+			for _, node := range nodes {
+				if node.Name == name {
+					track.Id, err = strconv.Atoi(node.Index)
+					if err != nil {
+						log.Fatal(err)
+					}
+				}
+			}
+			if track.Id < 0 {
+				track.Id = nextId
+				nextId++
+			}
+			tracks = append(tracks, track)
+		}
+
+		var trace interface{}
+
+		switch *traceFormat {
+		case "chrome":
+			trace = chromeTrace(tracks)
+		case "otlp":
+			trace = otlpTrace(tracks, *traceYear)
+		default:
+			log.Fatal("format must be chrome or otlp")
+		}
+
+		file, err := os.Create(*traceOut)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		err = json.NewEncoder(file).Encode(trace)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("wrote", *traceOut)
+	},
+}
+
+//traceTrack is one validator's spans
+type traceTrack struct {
+	Name  string
+	Id    int
+	Spans []reader.Span
+}
+
+//*********************************************************************chrome trace-event format***********************************************
+
+type chromeEvent struct {
+	Name  string            `json:"name"`
+	Cat   string            `json:"cat,omitempty"`
+	Ph    string            `json:"ph"`
+	Ts    int64             `json:"ts"`
+	Dur   int64             `json:"dur,omitempty"`
+	Scope string            `json:"s,omitempty"`
+	Pid   int               `json:"pid"`
+	Tid   int               `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+//chromeTrace lays every span out as a complete ("X") event and every arrival as an instant ("i") event, in microseconds
+//from the earliest span; spans on a track nest by time
+func chromeTrace(tracks []traceTrack) map[string]interface{} {
+	var events []chromeEvent
+	var origin time.Time
+
+	for _, track := range tracks {
+		if len(track.Spans) > 0 && (origin.IsZero() || track.Spans[0].Start.Before(origin)) {
+			origin = track.Spans[0].Start
+		}
+	}
+
+	micros := func(t time.Time) int64 {
+		return t.Sub(origin).Nanoseconds() / int64(time.Microsecond)
+	}
+
+	var add func(track traceTrack, span reader.Span)
+	add = func(track traceTrack, span reader.Span) {
+		events = append(events, chromeEvent{
+			Name: span.Name,
+			Cat:  span.Kind,
+			Ph:   "X",
+			Ts:   micros(span.Start),
+			Dur:  micros(span.End) - micros(span.Start),
+			Tid:  track.Id,
+			Args: map[string]string{"height": strconv.Itoa(span.Height), "round": strconv.Itoa(span.Round)},
+		})
+
+		for _, event := range span.Events {
+			events = append(events, chromeEvent{Name: event.Name, Cat: "arrival", Ph: "i", Scope: "t", Ts: micros(event.Time), Tid: track.Id, Args: event.Attrs})
+		}
+
+		for _, child := range span.Children {
+			add(track, child)
+		}
+	}
+
+	for _, track := range tracks {
+		events = append(events, chromeEvent{Name: "thread_name", Ph: "M", Tid: track.Id, Args: map[string]string{"name": track.Name}})
+
+		for _, span := range track.Spans {
+			add(track, span)
+		}
+	}
+
+	return map[string]interface{}{"traceEvents": events, "displayTimeUnit": "ms"}
+}
+
+//*********************************************************************OTLP JSON format***********************************************
+
+type otlpAttr struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+type otlpEvent struct {
+	Name       string     `json:"name"`
+	Time       string     `json:"timeUnixNano"`
+	Attributes []otlpAttr `json:"attributes,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId    string      `json:"traceId"`
+	SpanId     string      `json:"spanId"`
+	ParentId   string      `json:"parentSpanId,omitempty"`
+	Name       string      `json:"name"`
+	Kind       int         `json:"kind"`
+	Start      string      `json:"startTimeUnixNano"`
+	End        string      `json:"endTimeUnixNano"`
+	Attributes []otlpAttr  `json:"attributes"`
+	Events     []otlpEvent `json:"events,omitempty"`
+}
+
+//otlpTrace writes a resource (service) per validator. Every validator's spans for a height share a trace id, so a height
+//can be viewed across the whole network
+func otlpTrace(tracks []traceTrack, year int) map[string]interface{} {
+	var resourceSpans []interface{}
+
+	nanos := func(t time.Time) string {
+		return strconv.FormatInt(t.AddDate(year, 0, 0).UnixNano(), 10)
+	}
+
+	for _, track := range tracks {
+		var spans []otlpSpan
+
+		var add func(span reader.Span, parentId string, path string)
+		add = func(span reader.Span, parentId string, path string) {
+			spanId := otlpSpanId(track.Name + "/" + path)
+
+			otlp := otlpSpan{
+				TraceId:  fmt.Sprintf("%032x", span.Height),
+				SpanId:   spanId,
+				ParentId: parentId,
+				Name:     span.Name,
+				Kind:     1,
+				Start:    nanos(span.Start),
+				End:      nanos(span.End),
+				Attributes: []otlpAttr{
+					otlpInt("height", span.Height),
+					otlpInt("round", span.Round),
+					otlpString("kind", span.Kind),
+				},
+			}
+
+			for _, event := range span.Events {
+				var keys []string
+				for key := range event.Attrs {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				otlpEv := otlpEvent{Name: event.Name, Time: nanos(event.Time)}
+				for _, key := range keys {
+					otlpEv.Attributes = append(otlpEv.Attributes, otlpString(key, event.Attrs[key]))
+				}
+				otlp.Events = append(otlp.Events, otlpEv)
+			}
+
+			spans = append(spans, otlp)
+
+			for i, child := range span.Children {
+				add(child, spanId, path+"/"+strconv.Itoa(i))
+			}
+		}
+
+		for _, span := range track.Spans {
+			add(span, "", strconv.Itoa(span.Height))
+		}
+
+		resourceSpans = append(resourceSpans, map[string]interface{}{
+			"resource": map[string]interface{}{"attributes": []otlpAttr{otlpString("service.name", track.Name)}},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "t-logs"},
+				"spans": spans,
+			}},
+		})
+	}
+
+	return map[string]interface{}{"resourceSpans": resourceSpans}
+}
+
+//otlpSpanId derives a stable 8 byte span id from the span's place in the trace
+func otlpSpanId(path string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(path))
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+func otlpString(key string, value string) otlpAttr {
+	return otlpAttr{key, map[string]string{"stringValue": value}}
+}
+
+//OTLP JSON writes 64 bit ints as strings
+func otlpInt(key string, value int) otlpAttr {
+	return otlpAttr{key, map[string]string{"intValue": strconv.Itoa(value)}}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var drop *float64
+var minMsgs *int
+
+func init() {
+	RootCmd.AddCommand(TrafficCmd)
+
+	drop = TrafficCmd.PersistentFlags().Float64("drop", 0.5, "flag peers whose msg count falls to this fraction of the previous interval's or lower")
+	minMsgs = TrafficCmd.PersistentFlags().Int("min", 10, "ignore drops from intervals with fewer msgs than this")
+
+	viper.BindPFlag("traffic.drop", TrafficCmd.PersistentFlags().Lookup("drop"))
+	viper.BindPFlag("traffic.min", TrafficCmd.PersistentFlags().Lookup("min"))
+}
+
+var TrafficCmd = &cobra.Command{
+	Use:   "traffic",
+	Short: "See how many msgs (and roughly how many bytes) a given node has received from each peer, per interval",
+	Long: `For a given node over a given period of time, traffic will display the number of msgs and estimated bytes received from each peer at intervals of a given length, followed by the peers that sent the most and any peers whose msg rate suddenly dropped.
+
+	Each interval's row totals every channel, and is followed by a row per channel (chId) msgs arrived on.
+	Cells read msgs/bytes. Bytes are estimated from block parts and mempool txs only, as other msgs don't log their size.
+
+  Takes five args: interval length (500ms, 5s, 10m, 1h; a bare number is read as miliseconds), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 5 {
+			log.Fatal("5 args required: interval length (500ms, 5s, 10m, 1h), start date (01-01), start time (00:00:00[.000 if you'd like more specificity]), end date, and end time")
+		}
+
+		dur, err := parseInterval(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		trafficArr, err := reader.GetTraffic(entries, nodes, dur, args[1], args[2], args[3], args[4])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, traffic := range trafficArr {
+			var cells []string
+			var channels []string
+
+			for ch := range traffic.Msgs {
+				channels = append(channels, ch)
+			}
+			sort.Strings(channels)
+
+			for i := range nodes {
+				msgs, bytes := 0, 0
+				for _, ch := range channels {
+					msgs += traffic.Msgs[ch][i]
+					bytes += traffic.Bytes[ch][i]
+				}
+				cells = append(cells, trafficCell(msgs, bytes))
+			}
+			fmt.Println(traffic.Time, cells)
+
+			for _, ch := range channels {
+				var chCells []string
+				for i := range nodes {
+					chCells = append(chCells, trafficCell(traffic.Msgs[ch][i], traffic.Bytes[ch][i]))
+				}
+				fmt.Printf("  ch %-4s %v\n", ch, chCells)
+			}
+		}
+
+		talkers, err := reader.TopTalkers(trafficArr, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("\nTop talkers:")
+		for _, talker := range talkers {
+			fmt.Printf("%s msgs=%d bytes=%d channels=%v\n", talker.Name, talker.Msgs, talker.Bytes, talker.Channels)
+		}
+
+		drops, err := reader.RateDrops(trafficArr, nodes, *drop, *minMsgs)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("\nRate drops:")
+		for _, rateDrop := range drops {
+			fmt.Printf("%s %s %d -> %d msgs\n", rateDrop.Time, rateDrop.Name, rateDrop.Before, rateDrop.After)
+		}
+	},
+}
+
+//trafficCell reads msgs/bytes, or _ for no msgs
+func trafficCell(msgs int, bytes int) string {
+	if msgs == 0 {
+		return "_"
+	}
+	return fmt.Sprintf("%d/%d", msgs, bytes)
+}
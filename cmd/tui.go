@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/nsf/termbox-go"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	RootCmd.AddCommand(TuiCmd)
+}
+
+var TuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Step through a node's consensus state interactively",
+	Long: `For a given node, tui displays the consensus state (as in state) beside the log entries around a cursor, which can be moved through the log like a debugger.
+
+	n, right arrow = next entry
+	p, left arrow  = previous entry
+	N, down arrow  = next step
+	P, up arrow    = previous step
+	h              = jump to height
+	/              = search descrips, modules and values
+	f              = next search match
+	q, esc         = quit
+
+  Takes no args.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, nodes, err := loadLog()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(entries) == 0 {
+			log.Fatal("log is empty")
+		}
+
+		replay, err := reader.NewReplay(entries, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = termbox.Init()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = runTui(replay)
+		termbox.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+//tui input modes
+const (
+	modeNormal = iota
+	modeHeight
+	modeSearch
+)
+
+//runTui draws and handles keys until the user quits
+func runTui(replay *reader.Replay) error {
+	var input string
+	var query string
+	var message string
+
+	cursor := 0
+	mode := modeNormal
+	last := len(replay.Entries) - 1
+
+	for {
+		status, err := replay.StatusAt(cursor)
+		if err != nil {
+			return err
+		}
+
+		prompt := "n/p entry  N/P step  h height  / search  f next match  q quit  " + message
+		if mode == modeHeight {
+			prompt = "height: " + input
+		} else if mode == modeSearch {
+			prompt = "search: " + input
+		}
+
+		err = drawTui(replay, cursor, status, prompt)
+		if err != nil {
+			return err
+		}
+
+		event := termbox.PollEvent()
+		if event.Type == termbox.EventError {
+			return event.Err
+		}
+		if event.Type != termbox.EventKey {
+			continue
+		}
+
+		message = ""
+
+		//collect input for a height or search until enter (or esc to cancel)
+		if mode != modeNormal {
+			switch {
+			case event.Key == termbox.KeyEsc:
+				mode = modeNormal
+			case event.Key == termbox.KeyBackspace || event.Key == termbox.KeyBackspace2:
+				if len(input) > 0 {
+					input = input[:len(input)-1]
+				}
+			case event.Key == termbox.KeySpace:
+				input = input + " "
+			case event.Key == termbox.KeyEnter && mode == modeHeight:
+				height, err := strconv.Atoi(input)
+				if index := replay.FindHeight(height); err == nil && index >= 0 {
+					cursor = index
+				} else {
+					message = "height " + input + " not found"
+				}
+				mode = modeNormal
+			case event.Key == termbox.KeyEnter && mode == modeSearch:
+				query = input
+				cursor, message = search(replay, query, cursor)
+				mode = modeNormal
+			case event.Ch != 0:
+				input = input + string(event.Ch)
+			}
+			continue
+		}
+
+		switch {
+		case event.Ch == 'q' || event.Key == termbox.KeyEsc || event.Key == termbox.KeyCtrlC:
+			return nil
+		case event.Ch == 'n' || event.Key == termbox.KeyArrowRight:
+			if cursor < last {
+				cursor++
+			}
+		case event.Ch == 'p' || event.Key == termbox.KeyArrowLeft:
+			if cursor > 0 {
+				cursor--
+			}
+		case event.Ch == 'N' || event.Key == termbox.KeyArrowDown:
+			cursor = replay.NextStep(cursor, 1)
+		case event.Ch == 'P' || event.Key == termbox.KeyArrowUp:
+			cursor = replay.NextStep(cursor, -1)
+		case event.Ch == 'h':
+			mode, input = modeHeight, ""
+		case event.Ch == '/':
+			mode, input = modeSearch, ""
+		case event.Ch == 'f' && query != "":
+			cursor, message = search(replay, query, cursor)
+		}
+	}
+}
+
+//search moves the cursor to the next match for query, if there is one
+func search(replay *reader.Replay, query string, cursor int) (int, string) {
+	index := replay.Search(query, cursor)
+	if index < 0 {
+		return cursor, "no match for " + query
+	}
+	return index, ""
+}
+
+//drawTui draws the status panel on the left, log entries around the cursor on the right, and prompt along the bottom
+func drawTui(replay *reader.Replay, cursor int, status reader.Status, prompt string) error {
+	err := termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+	if err != nil {
+		return err
+	}
+
+	width, height := termbox.Size()
+	entry := replay.Entries[cursor]
+
+	panel := []string{
+		fmt.Sprintf("entry %d/%d  %s %s", cursor+1, len(replay.Entries), entry.Date, entry.Time),
+		"",
+		fmt.Sprintf("Height       %d", status.Height),
+		fmt.Sprintf("Round        %d", status.Round),
+		fmt.Sprintf("Step         %s", status.Step),
+		fmt.Sprintf("Proposal     %s", status.Proposal),
+		fmt.Sprintf("BlockParts   %v", status.BlockParts),
+		fmt.Sprintf("PreVotes     %v", status.PreVotes),
+		fmt.Sprintf("PreCommits   %v", status.PreCommits),
+		fmt.Sprintf("XPreVotes    %v", status.XPreVotes),
+		fmt.Sprintf("XPreCommits  %v", status.XPreCommits),
+	}
+
+	panelWidth := 48
+	for y, line := range panel {
+		drawText(0, y, panelWidth, line, termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	//keep the cursor in the middle of the log pane
+	rows := height - 1
+	first := cursor - rows/2
+	if first < 0 {
+		first = 0
+	}
+
+	for y := 0; y < rows && first+y < len(replay.Entries); y++ {
+		fg, bg := termbox.ColorDefault, termbox.ColorDefault
+		if first+y == cursor {
+			fg, bg = termbox.ColorBlack, termbox.ColorWhite
+		}
+		drawText(panelWidth+2, y, width-panelWidth-2, filefuncs.MarshalLine(replay.Entries[first+y]), fg, bg)
+	}
+
+	drawText(0, height-1, width, prompt, termbox.ColorDefault|termbox.AttrBold, termbox.ColorDefault)
+
+	return termbox.Flush()
+}
+
+//drawText writes text at x, y, cut off at width cells
+func drawText(x int, y int, width int, text string, fg termbox.Attribute, bg termbox.Attribute) {
+	i := 0
+	for _, ch := range text {
+		if i >= width {
+			return
+		}
+		termbox.SetCell(x+i, y, ch, fg, bg)
+		i++
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/filefuncs"
+	"github.com/joshkestenberg/t-logs/reader"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var txPrefix *string
+var minWait *string
+
+func init() {
+	RootCmd.AddCommand(TxsCmd)
+
+	txPrefix = TxsCmd.PersistentFlags().String("tx", "", "only txs starting with these hex bytes")
+	minWait = TxsCmd.PersistentFlags().String("min-wait", "0", "only txs that took at least this long to be included, or never were (eg. 30s, 2m)")
+
+	viper.BindPFlag("txs.tx", TxsCmd.PersistentFlags().Lookup("tx"))
+	viper.BindPFlag("txs.min-wait", TxsCmd.PersistentFlags().Lookup("min-wait"))
+}
+
+var TxsCmd = &cobra.Command{
+	Use:   "txs",
+	Short: "Trace transactions through every node's mempool to their block",
+	Long: `txs follows each transaction across the cluster's mempools: where and when it was first seen and which peer delivered it, each node's CheckTx result, how many rechecks it sat through, and the height it was included at, with the time from first seen to that block's commit.
+
+	Txs are identified by their bytes, in hex, as the mempool logs them. A tx counts as included in the last block a node committed before removing it from its mempool.
+	from         the peer that delivered the tx, or "submitted" if it came in directly (eg. over RPC)
+	result       OK, or the response the tx was rejected with
+
+  Takes any number of args: rendered log files (by default, every log named in nodes.json).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nodes, err := filefuncs.UnmarshalNodes()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wait, err := parseInterval(*minWait)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		logs, err := loadCluster(args, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		traces, err := reader.GetTxs(logs, nodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, trace := range traces {
+			if !strings.HasPrefix(trace.Tx, strings.ToUpper(*txPrefix)) || (trace.Height > 0 && trace.Wait < wait) {
+				continue
+			}
+
+			included := "not included"
+			if trace.Height > 0 {
+				included = fmt.Sprintf("included at height %d, %s %s (%v)", trace.Height, trace.IncDate, trace.IncTime, trace.Wait)
+			}
+			fmt.Printf("%s: first seen %s %s at %s from %s; %s\n", trace.Tx, trace.Date, trace.Time, trace.Node, txFrom(trace.From), included)
+
+			for _, node := range trace.Nodes {
+				removed := ""
+				if node.Height > 0 {
+					removed = fmt.Sprintf(" removed after height %d", node.Height)
+				}
+				fmt.Printf("  %s %s %s from %s: %s rechecks=%d%s\n", node.Node, node.Date, node.Time, txFrom(node.From), node.Result, node.Rechecks, removed)
+			}
+		}
+	},
+}
+
+func txFrom(from string) string {
+	if from == "" {
+		return "submitted"
+	}
+	return from
+}
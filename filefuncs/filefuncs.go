@@ -3,9 +3,12 @@ package filefuncs
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/joshkestenberg/t-logs/reader"
 )
@@ -43,11 +46,8 @@ func RenderDoc(file *os.File, name string) (string, error) {
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		str := scanner.Text()
-		if strings.Contains(str, `|`) {
-			if strings.Contains(str, "Block{") {
-				str = str + "}"
-			}
+		str, ok := renderLine(scanner.Text())
+		if ok {
 			_, err = renderFile.WriteString(str + "\n")
 			if err != nil {
 				return renderName, err
@@ -58,25 +58,27 @@ func RenderDoc(file *os.File, name string) (string, error) {
 	return renderName, err
 }
 
-//UnmarshalLines converts given lines to an array of structs
-func UnmarshalLines(file *os.File) ([]reader.LogEntry, error) {
-	var str string
-	var err error
-	var entries []reader.LogEntry
+//renderLine preps one line for parse, as RenderDoc does, and reports whether the line is a log entry at all
+func renderLine(str string) (string, bool) {
+	if !strings.Contains(str, `|`) {
+		return str, false
+	}
 
-	//intialize scanner and scan each line
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		//scan each applicable line, make struct, add struct to []struct
-		str = scanner.Text()
-		entry, err := UnmarshalLine(str)
-		if err != nil {
-			return nil, err
-		}
-		entries = append(entries, entry)
+	if strings.Contains(str, "Block{") {
+		str = str + "}"
 	}
+	return str, true
+}
+
+//UnmarshalLines converts given lines to an array of structs, parsing chunks of the file concurrently (see ParseLines)
+func UnmarshalLines(file *os.File) ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
 
-	if err := scanner.Err(); err != nil {
+	err := ParseLines(file, func(chunk []reader.LogEntry) error {
+		entries = append(entries, chunk...)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -156,38 +158,54 @@ func UnmarshalLine(line string) (reader.LogEntry, error) {
 	return entry, err
 }
 
-//populates Nodes and writes to json file
-func GetNodes(filenames []string) error {
-	var str string
-	var err error
-	var saved bool
-	var nodes []reader.Node
-
-	for _, filename := range filenames {
+//MarshalLine turns an entry back into a log line, as UnmarshalLine would read it (other values are written in key order)
+func MarshalLine(entry reader.LogEntry) string {
+	line := fmt.Sprintf("%s[%s|%s] %-44s module=%s", entry.Level, entry.Date, entry.Time, entry.Descrip, entry.Module)
 
-		file, err := os.Open(filename)
+	var keys []string
+	for key := range entry.Other {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		if err != nil {
-			return err
+	for _, key := range keys {
+		value := entry.Other[key]
+		if value == "" || strings.ContainsAny(value, " =") {
+			value = `"` + value + `"`
 		}
+		line = line + " " + key + "=" + value
+	}
 
-		node := reader.Node{}
+	return line
+}
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			str = scanner.Text()
-			entry, err := UnmarshalLine(str)
+//populates Nodes and writes to json file, reading every log at once
+func GetNodes(filenames []string) error {
+	var err error
+	var nodes []reader.Node
 
-			nodes, saved = node.Save(nodes)
-			if saved == true {
-				break
-			} else {
-				node.Populate(entry, filename)
-			}
+	found := make([]reader.Node, len(filenames))
+	saved := make([]bool, len(filenames))
+	errs := make([]error, len(filenames))
+
+	var wg sync.WaitGroup
+	for i, filename := range filenames {
+		wg.Add(1)
+		go func(i int, filename string) {
+			defer wg.Done()
+			found[i], saved[i], errs[i] = findNode(filename)
+		}(i, filename)
+	}
+	wg.Wait()
 
-			if err = scanner.Err(); err != nil {
-				return err
-			}
+	//nodes are kept in the order their logs were given
+	for i := range filenames {
+		if errs[i] != nil {
+			return errs[i]
+		}
+
+		if saved[i] {
+			nodes = append(nodes, found[i])
 		}
 	}
 
@@ -206,6 +224,33 @@ func GetNodes(filenames []string) error {
 	return err
 }
 
+//findNode reads a log until it has found everything about its node, reporting whether it did
+func findNode(filename string) (reader.Node, bool, error) {
+	var saved bool
+	var nodes []reader.Node
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return reader.Node{}, false, err
+	}
+	defer file.Close()
+
+	node := reader.Node{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, _ := UnmarshalLine(scanner.Text())
+
+		nodes, saved = node.Save(nodes)
+		if saved == true {
+			return nodes[0], true, nil
+		}
+		node.Populate(entry, filename)
+	}
+
+	return node, false, scanner.Err()
+}
+
 //takes populated Node and writes to json file
 func MarshalJSON(nodes []reader.Node, file *os.File) error {
 	var err error
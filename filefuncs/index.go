@@ -0,0 +1,329 @@
+package filefuncs
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//a Mark is saved every indexInterval lines, so a stalled height never means reading far past where we need to be
+const indexInterval = 10000
+
+//how much of each end of the log goes into its checksum
+const checksumSpan = 64 * 1024
+
+//Index maps a rendered log's heights and times to byte offsets, so a read can start near where it's needed rather than
+//at the top. It's saved beside the log (as log.idx) with a checksum of the log's size, head and tail; a log that's
+//changed since gets a new index
+type Index struct {
+	Checksum string
+	Listener int64
+	Heights  []Mark
+	Marks    []Mark
+}
+
+//Mark is a line the index can seek to
+type Mark struct {
+	Height int
+	Line   int
+	Offset int64
+	Date   string
+	Time   string
+}
+
+//LoadIndex returns the log's saved index, or builds and saves a new one if there's none or the log has changed
+func LoadIndex(name string) (Index, error) {
+	var index Index
+
+	checksum, err := logChecksum(name)
+	if err != nil {
+		return index, err
+	}
+
+	bytes, err := ioutil.ReadFile(name + ".idx")
+	if err == nil && json.Unmarshal(bytes, &index) == nil && index.Checksum == checksum {
+		return index, nil
+	}
+
+	index, err = BuildIndex(name)
+	if err != nil {
+		return index, err
+	}
+	index.Checksum = checksum
+
+	bytes, err = json.Marshal(index)
+	if err != nil {
+		return index, err
+	}
+
+	return index, ioutil.WriteFile(name+".idx", bytes, 0600)
+}
+
+//BuildIndex reads the whole log once, marking where each height begins (its first round is entered) and every
+//indexInterval lines. Blank lines and raw lines that aren't entries are skipped
+func BuildIndex(name string) (Index, error) {
+	index := Index{Listener: -1}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return index, err
+	}
+	defer file.Close()
+
+	var offset int64
+	var height int
+	var nextMark int
+
+	r := bufio.NewReader(file)
+	for line := 0; ; line++ {
+		str, err := r.ReadString('\n')
+		if err == io.EOF && str == "" {
+			break
+		} else if err != nil && err != io.EOF {
+			return index, err
+		}
+
+		entry, ok := indexLine(strings.TrimSuffix(str, "\n"))
+		if !ok {
+			offset += int64(len(str))
+			continue
+		}
+
+		if entry.Descrip == "Starting DefaultListener" && index.Listener < 0 {
+			index.Listener = offset
+		}
+
+		var h, round int
+		if _, err := fmt.Sscanf(entry.Descrip, "enterNewRound(%d/%d)", &h, &round); err == nil && h != height {
+			height = h
+			index.Heights = append(index.Heights, Mark{height, line, offset, entry.Date, entry.Time})
+		}
+
+		if line >= nextMark {
+			index.Marks = append(index.Marks, Mark{height, line, offset, entry.Date, entry.Time})
+			nextMark = line + indexInterval
+		}
+
+		offset += int64(len(str))
+	}
+
+	return index, nil
+}
+
+//ReadIndexed reads the entries from stD stT through enD enT (to whatever precision they're given in), starting at the last
+//mark before the start time, led by the node's listener entry so its IP can still be found. With wholeHeights, reading
+//starts instead where the height underway at the start time began, so its Status can be rebuilt from there; if nothing
+//was logged from the start time through the end, the rest of the log is read, so GetStatus gives the final state as it
+//does for the whole log. Logs carry no year, so one is carried forward whenever the month goes backwards
+func ReadIndexed(name string, index Index, stD string, stT string, enD string, enT string, wholeHeights bool) ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
+
+	file, err := os.Open(name)
+	if err != nil {
+		return entries, err
+	}
+	defer file.Close()
+
+	if index.Listener >= 0 {
+		entry, _, err := readLineAt(file, index.Listener)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	start, end, err := indexBounds(index, stD, stT, enD, enT)
+	if err != nil {
+		return entries, err
+	}
+
+	marks := index.Marks
+	if wholeHeights {
+		marks = index.Heights
+	}
+
+	offset, rollover, err := lastBefore(marks, start)
+	if err != nil {
+		return entries, err
+	}
+
+	//the listener entry has already been read if that's where we start
+	if offset == index.Listener && len(entries) > 0 {
+		_, length, err := readLineAt(file, offset)
+		if err != nil {
+			return entries, err
+		}
+		offset += length
+	}
+
+	_, err = file.Seek(offset, 0)
+	if err != nil {
+		return entries, err
+	}
+
+	var found bool
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, ok := indexLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		entryT, err := rollover.EntryTime(entry)
+		if err != nil {
+			continue
+		}
+
+		if !entryT.Before(end) {
+			if found || !wholeHeights {
+				break
+			}
+		} else if !entryT.Before(start) {
+			found = true
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+//*********************************************************************following functions belong to Index***********************************************
+
+//logChecksum hashes the log's size with its first and last checksumSpan bytes, which is enough to tell a rotated,
+//truncated, rewritten or appended log from the one indexed without reading all of it
+func logChecksum(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprint(hash, info.Size())
+
+	head := make([]byte, checksumSpan)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	hash.Write(head[:n])
+
+	if info.Size() > checksumSpan {
+		tail := make([]byte, checksumSpan)
+		n, err = file.ReadAt(tail, info.Size()-checksumSpan)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		hash.Write(tail[:n])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+//indexBounds converts the times ReadIndexed is given, the end being just past whatever precision it's given in (04:33 runs
+//through 04:33:59.999), into the year of the log's first mark
+func indexBounds(index Index, stD string, stT string, enD string, enT string) (time.Time, time.Time, error) {
+	start, _, err := reader.ParseBound(stD, stT)
+	if err != nil {
+		return start, start, err
+	}
+
+	end, precision, err := reader.ParseBound(enD, enT)
+	if err != nil {
+		return start, end, err
+	}
+	end = end.Add(precision)
+
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
+
+	if len(index.Marks) > 0 {
+		first, err := reader.EntryTime(reader.LogEntry{Date: index.Marks[0].Date, Time: index.Marks[0].Time})
+		if err != nil {
+			return start, end, err
+		}
+
+		//a log that starts in a later month than the times given must have run into the next year
+		if first.Month() > start.Month() {
+			start = start.AddDate(1, 0, 0)
+			end = end.AddDate(1, 0, 0)
+		}
+	}
+	return start, end, nil
+}
+
+//lastBefore returns the offset of the last mark before the given time, or 0 if there's none, along with the years
+//carried up to that mark, from which to carry on reading
+func lastBefore(marks []Mark, at time.Time) (int64, reader.YearRollover, error) {
+	var offset int64
+	var rollover reader.YearRollover
+
+	for _, mark := range marks {
+		next := rollover
+		markT, err := next.EntryTime(reader.LogEntry{Date: mark.Date, Time: mark.Time})
+		if err != nil {
+			return offset, rollover, err
+		}
+
+		if !markT.Before(at) {
+			break
+		}
+		offset, rollover = mark.Offset, next
+	}
+	return offset, rollover, nil
+}
+
+//indexLine reads a line as UnmarshalLine does, reporting false for a blank line or a raw one it can't read (eg. a panic
+//trace) rather than letting UnmarshalLine index past what's there
+func indexLine(line string) (reader.LogEntry, bool) {
+	open := strings.Index(line, "[")
+	if open < 0 {
+		return reader.LogEntry{}, false
+	}
+
+	closing := strings.Index(line[open:], "]")
+	if closing < 0 || !strings.Contains(line[open:open+closing], "|") {
+		return reader.LogEntry{}, false
+	}
+
+	rest := line[open+closing:]
+	if !strings.Contains(rest, "Block{") && !strings.Contains(rest, "module") {
+		return reader.LogEntry{}, false
+	}
+
+	entry, err := UnmarshalLine(line)
+	return entry, err == nil
+}
+
+//readLineAt reads the entry on the line at offset, returning it with the line's length
+func readLineAt(file *os.File, offset int64) (reader.LogEntry, int64, error) {
+	_, err := file.Seek(offset, 0)
+	if err != nil {
+		return reader.LogEntry{}, 0, err
+	}
+
+	str, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return reader.LogEntry{}, 0, err
+	}
+
+	entry, err := UnmarshalLine(strings.TrimSuffix(str, "\n"))
+	return entry, int64(len(str)), err
+}
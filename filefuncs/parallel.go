@@ -0,0 +1,154 @@
+package filefuncs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//a chunk is cut at the first newline after chunkSize bytes
+const chunkSize = 4 * 1024 * 1024
+
+//chunk is a run of whole lines, numbered so parsed chunks can be put back in order
+type chunk struct {
+	seq     int
+	data    []byte
+	entries []reader.LogEntry
+	err     error
+}
+
+//ParseLines splits r into chunks at newline boundaries, parses the chunks concurrently (a worker per CPU), and hands the
+//entries of each chunk to each, in order. No more than two chunks per worker are held at once, however large r is
+func ParseLines(r io.Reader, each func([]reader.LogEntry) error) error {
+	workers := runtime.NumCPU()
+
+	//a token is taken for every chunk read, and given back once the chunk has been handed on, bounding memory
+	tokens := make(chan struct{}, 2*workers)
+	todo := make(chan *chunk)
+	done := make(chan *chunk)
+	stop := make(chan struct{})
+
+	var readErr error
+
+	go func() {
+		defer close(todo)
+
+		br := bufio.NewReaderSize(r, chunkSize)
+		for seq := 0; ; seq++ {
+			select {
+			case <-stop:
+				return
+			case tokens <- struct{}{}:
+			}
+
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			data, err := readChunk(br)
+			if len(data) > 0 {
+				todo <- &chunk{seq: seq, data: data}
+			}
+
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				readErr = err
+				return
+			}
+		}
+	}()
+
+	finished := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c := range todo {
+				c.entries, c.err = parseChunk(c.data)
+				c.data = nil
+				done <- c
+			}
+			finished <- struct{}{}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < workers; i++ {
+			<-finished
+		}
+		close(done)
+	}()
+
+	//chunks finish out of order, so hold each until those before it have been handed on
+	var err error
+	var next int
+	held := make(map[int]*chunk)
+
+	for c := range done {
+		held[c.seq] = c
+
+		for c, ok := held[next]; ok; c, ok = held[next] {
+			delete(held, next)
+			next++
+			<-tokens
+
+			if err != nil {
+				continue
+			}
+
+			err = c.err
+			if err == nil {
+				err = each(c.entries)
+			}
+
+			//stop reading, but let the workers drain what's already been read
+			if err != nil {
+				close(stop)
+			}
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	return readErr
+}
+
+//*********************************************************************following functions belong to ParseLines***********************************************
+
+//readChunk reads about chunkSize bytes, through the end of the line it stops in
+func readChunk(br *bufio.Reader) ([]byte, error) {
+	data := make([]byte, chunkSize)
+
+	n, err := io.ReadFull(br, data)
+	data = data[:n]
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && n == 0) {
+		return data, io.EOF
+	} else if err != nil {
+		return data, err
+	}
+
+	rest, err := br.ReadBytes('\n')
+	return append(data, rest...), err
+}
+
+//parseChunk parses each line of a chunk, as a bufio.Scanner would split them
+func parseChunk(data []byte) ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
+
+	data = bytes.TrimSuffix(data, []byte("\n"))
+
+	for _, line := range strings.Split(string(data), "\n") {
+		entry, err := UnmarshalLine(strings.TrimSuffix(line, "\r"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
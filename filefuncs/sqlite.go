@@ -0,0 +1,125 @@
+package filefuncs
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//schema is created if it's missing, so one database can take logs from several imports. Every table is keyed by node
+//and line (the entry's index in its log), so derived rows can be joined back to the entry they came from
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS entries (node TEXT, line INTEGER, level TEXT, date TEXT, time TEXT, descrip TEXT, module TEXT, other TEXT)`,
+	`CREATE TABLE IF NOT EXISTS votes (node TEXT, line INTEGER, date TEXT, time TEXT, height INTEGER, round INTEGER, type TEXT, validator TEXT, nil INTEGER, sent INTEGER, peer TEXT)`,
+	`CREATE TABLE IF NOT EXISTS proposals (node TEXT, line INTEGER, date TEXT, time TEXT, height INTEGER, round INTEGER, event TEXT, proposer TEXT)`,
+	`CREATE TABLE IF NOT EXISTS block_parts (node TEXT, line INTEGER, date TEXT, time TEXT, height INTEGER, round INTEGER, part INTEGER, peer TEXT)`,
+	`CREATE TABLE IF NOT EXISTS steps (node TEXT, line INTEGER, date TEXT, time TEXT, height INTEGER, round INTEGER, step TEXT)`,
+	`CREATE TABLE IF NOT EXISTS peers (node TEXT, line INTEGER, date TEXT, time TEXT, event TEXT, peer TEXT, ip TEXT, direction TEXT, err TEXT)`,
+
+	`CREATE INDEX IF NOT EXISTS entries_line ON entries (node, line)`,
+	`CREATE INDEX IF NOT EXISTS entries_time ON entries (date, time)`,
+	`CREATE INDEX IF NOT EXISTS votes_time ON votes (date, time)`,
+	`CREATE INDEX IF NOT EXISTS votes_height ON votes (height, round)`,
+	`CREATE INDEX IF NOT EXISTS proposals_time ON proposals (date, time)`,
+	`CREATE INDEX IF NOT EXISTS proposals_height ON proposals (height, round)`,
+	`CREATE INDEX IF NOT EXISTS block_parts_time ON block_parts (date, time)`,
+	`CREATE INDEX IF NOT EXISTS block_parts_height ON block_parts (height, round)`,
+	`CREATE INDEX IF NOT EXISTS steps_time ON steps (date, time)`,
+	`CREATE INDEX IF NOT EXISTS steps_height ON steps (height, round)`,
+	`CREATE INDEX IF NOT EXISTS peers_time ON peers (date, time)`,
+}
+
+//tables written by ImportSQL, in the order they're written
+var tables = []string{"entries", "votes", "proposals", "block_parts", "steps", "peers"}
+
+//ImportSQL writes a node's entries (other values as a JSON object) and the records derived from them to db, in one
+//transaction, replacing whatever was imported for that node before
+func ImportSQL(db *sql.DB, node string, entries []reader.LogEntry, records reader.Records) error {
+	for _, stmt := range schema {
+		_, err := db.Exec(stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		_, err = tx.Exec(`DELETE FROM `+table+` WHERE node = ?`, node)
+		if err != nil {
+			return err
+		}
+	}
+
+	insert := func(query string, rows int, values func(i int) []interface{}) error {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i := 0; i < rows; i++ {
+			_, err = stmt.Exec(append([]interface{}{node}, values(i)...)...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = insert(`INSERT INTO entries VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, len(entries), func(i int) []interface{} {
+		entry := entries[i]
+		other, _ := json.Marshal(entry.Other)
+		return []interface{}{i, entry.Level, entry.Date, entry.Time, entry.Descrip, entry.Module, string(other)}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insert(`INSERT INTO votes VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, len(records.Votes), func(i int) []interface{} {
+		vote := records.Votes[i]
+		return []interface{}{vote.Line, vote.Date, vote.Time, vote.Height, vote.Round, vote.Type, vote.Validator, vote.Nil, vote.Sent, vote.From}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insert(`INSERT INTO proposals VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, len(records.Proposals), func(i int) []interface{} {
+		proposal := records.Proposals[i]
+		return []interface{}{proposal.Line, proposal.Date, proposal.Time, proposal.Height, proposal.Round, proposal.Event, proposal.Proposer}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insert(`INSERT INTO block_parts VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, len(records.BlockParts), func(i int) []interface{} {
+		part := records.BlockParts[i]
+		return []interface{}{part.Line, part.Date, part.Time, part.Height, part.Round, part.Part, part.From}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insert(`INSERT INTO steps VALUES (?, ?, ?, ?, ?, ?, ?)`, len(records.Steps), func(i int) []interface{} {
+		step := records.Steps[i]
+		return []interface{}{step.Line, step.Date, step.Time, step.Height, step.Round, step.Step}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = insert(`INSERT INTO peers VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, len(records.Peers), func(i int) []interface{} {
+		peer := records.Peers[i]
+		return []interface{}{peer.Line, peer.Date, peer.Time, peer.Event, peer.Name, peer.Ip, peer.Direction, peer.Err}
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
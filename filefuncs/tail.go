@@ -0,0 +1,111 @@
+package filefuncs
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//Tail follows a log that's still being written, as tail -F does: each read picks up where the last left off, and if the
+//file is truncated, or rotated out and replaced, reading starts over from the top of the new file
+type Tail struct {
+	name    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial string
+}
+
+//OpenTail opens a log to follow from its start
+func OpenTail(name string) (*Tail, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Tail{name: name, file: file, info: info}, err
+}
+
+//Next returns the entries on every complete line written since the last call (raw or rendered lines alike);
+//a line still being written is held back until it's finished
+func (tail *Tail) Next() ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
+
+	info, err := os.Stat(tail.name)
+
+	//a rotated log may be briefly missing, in which case there's nothing new to read but what's left in the old file
+	if err != nil && !os.IsNotExist(err) {
+		return entries, err
+	}
+
+	if err == nil && !os.SameFile(info, tail.info) {
+		//finish the old file before moving on to its replacement
+		entries, err = tail.read()
+		if err != nil {
+			return entries, err
+		}
+
+		file, err := os.Open(tail.name)
+		if err != nil {
+			return entries, err
+		}
+
+		tail.file.Close()
+		tail.file, tail.info, tail.offset, tail.partial = file, info, 0, ""
+	} else if err == nil && info.Size() < tail.offset {
+		tail.offset, tail.partial = 0, ""
+	}
+
+	more, err := tail.read()
+	return append(entries, more...), err
+}
+
+//Close closes the file being followed
+func (tail *Tail) Close() error {
+	return tail.file.Close()
+}
+
+//*********************************************************************following functions belong to Tail***********************************************
+
+//read parses everything from the offset to the end of the current file
+func (tail *Tail) read() ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
+
+	_, err := tail.file.Seek(tail.offset, 0)
+	if err != nil {
+		return entries, err
+	}
+
+	bytes, err := ioutil.ReadAll(tail.file)
+	if err != nil {
+		return entries, err
+	}
+	tail.offset += int64(len(bytes))
+
+	lines := strings.Split(tail.partial+string(bytes), "\n")
+
+	//the last line is only complete once its newline is written
+	tail.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		str, ok := renderLine(line)
+		if !ok {
+			continue
+		}
+
+		entry, err := UnmarshalLine(str)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, err
+}
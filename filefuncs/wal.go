@@ -0,0 +1,378 @@
+package filefuncs
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+//a frame longer than this can only be a corrupt length, so it's never allocated
+const maxWALFrame = 10 * 1024 * 1024
+
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+//WAL message types, as go-wire registers them
+const (
+	walRoundState = 0x01
+	walMsgInfo    = 0x02
+	walTimeout    = 0x03
+	walEndHeight  = 0x04
+)
+
+//consensus message types within a msgInfo; only these reach the consensus state's queue, so only these are written
+const (
+	walProposal  = 0x11
+	walBlockPart = 0x13
+	walVote      = 0x14
+)
+
+//round step types, as timeouts carry them
+var walSteps = []string{"", "RoundStepNewHeight", "RoundStepNewRound", "RoundStepPropose", "RoundStepPrevote", "RoundStepPrevoteWait",
+	"RoundStepPrecommit", "RoundStepPrecommitWait", "RoundStepCommit"}
+
+//ReadWAL decodes a consensus write-ahead log (cs.wal) into the entries the same events would have been logged as, so
+//it can be read wherever a rendered log can. Each frame is a CRC32 (Castagnoli) of its data and the data's length, both
+//4 byte big-endian, then a go-wire TimedWALMessage: the time in nanoseconds (8 byte big-endian) and a typed message.
+//
+//Round state changes become steps (eg. enterPrevote(8/0)), timeouts "Received tock", the end of a height "#ENDHEIGHT",
+//and queued messages either "Receive" from the peer (matched to nodes by pubkey) or, with no peer, our own signed votes
+//and proposals. A proposal's block is "Received complete proposal block" once all its parts are in. When our own votes
+//name us in nodes, our listener entry leads, so our IP is known. A frame cut short at the end (a crash mid-write) is
+//dropped; a frame that fails its CRC is an error
+func ReadWAL(r io.Reader, nodes []reader.Node) ([]reader.LogEntry, error) {
+	var entries []reader.LogEntry
+	wal := &walState{nodes: nodes, totals: make(map[string]int), parts: make(map[string]map[int]bool)}
+
+	var offset int64
+	header := make([]byte, 8)
+
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return entries, err
+		}
+
+		sum := binary.BigEndian.Uint32(header[:4])
+		length := binary.BigEndian.Uint32(header[4:])
+		if length > maxWALFrame {
+			return entries, fmt.Errorf("corrupt WAL frame at offset %d: length %d", offset, length)
+		}
+
+		data := make([]byte, length)
+		_, err = io.ReadFull(r, data)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return entries, err
+		}
+
+		if crc32.Checksum(data, walTable) != sum {
+			return entries, fmt.Errorf("corrupt WAL frame at offset %d: checksum mismatch", offset)
+		}
+
+		frame, err := wal.decode(data)
+		if err != nil {
+			return entries, fmt.Errorf("bad WAL frame at offset %d: %v", offset, err)
+		}
+		entries = append(entries, frame...)
+
+		offset += int64(len(header)) + int64(length)
+	}
+
+	if wal.listener != "" && len(entries) > 0 {
+		listener := reader.LogEntry{Level: "I", Date: entries[0].Date, Time: entries[0].Time, Descrip: "Starting DefaultListener", Module: "p2p",
+			Other: map[string]string{"impl": "Listener(@" + wal.listener + ":46656)"}}
+		entries = append([]reader.LogEntry{listener}, entries...)
+	}
+	return entries, nil
+}
+
+//IsWAL reports whether the file is a consensus WAL rather than a rendered log, by its name (eg. cs.wal, cs.wal.000)
+func IsWAL(name string) bool {
+	base := name[strings.LastIndex(name, "/")+1:]
+	return strings.HasSuffix(base, ".wal") || strings.Contains(base, ".wal.") || base == "wal"
+}
+
+//*********************************************************************following functions belong to ReadWAL***********************************************
+
+//walState is what decoding needs to remember between frames
+type walState struct {
+	nodes    []reader.Node
+	listener string
+	totals   map[string]int
+	parts    map[string]map[int]bool
+}
+
+//walDecoder reads go-wire values from a frame, holding the first error so reads can be chained
+type walDecoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errShortFrame = errors.New("frame ends mid-message")
+
+//next returns the next n bytes, or nil once anything has gone wrong; lengths come from the frame itself, so they're checked
+//against what's left of it before they're trusted
+func (d *walDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 {
+		d.err = fmt.Errorf("bad length %d", n)
+		return nil
+	}
+	if n > len(d.data)-d.pos {
+		d.err = errShortFrame
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *walDecoder) byte() byte {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *walDecoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+//varint reads a go-wire int: a byte giving the number of big-endian bytes that follow (its high nibble set if negative)
+func (d *walDecoder) varint() int {
+	size := d.byte()
+	negative := size&0xF0 != 0
+	size &= 0x0F
+	if size > 8 {
+		d.err = fmt.Errorf("bad varint size %d", size)
+		return 0
+	}
+
+	var value uint64
+	for _, b := range d.next(int(size)) {
+		value = value<<8 | uint64(b)
+	}
+	if negative {
+		return -int(value)
+	}
+	return int(value)
+}
+
+//bytes reads a go-wire byte slice: a varint length, then the bytes
+func (d *walDecoder) bytes() []byte {
+	return d.next(d.varint())
+}
+
+//signature reads a go-wire crypto.Signature: a type byte (0 for none), then 64 bytes for ed25519 or a byte slice for secp256k1
+func (d *walDecoder) signature() []byte {
+	switch d.byte() {
+	case 0x00:
+		return nil
+	case 0x01:
+		return d.next(64)
+	}
+	return d.bytes()
+}
+
+//decode turns one TimedWALMessage into entries
+func (wal *walState) decode(data []byte) ([]reader.LogEntry, error) {
+	d := &walDecoder{data: data}
+
+	at := time.Unix(0, d.int64()).UTC()
+	entry := reader.LogEntry{Level: "I", Date: at.Format("01-02"), Time: at.Format("15:04:05.000"), Module: "consensus", Other: map[string]string{}}
+
+	var entries []reader.LogEntry
+
+	switch kind := d.byte(); kind {
+	case walRoundState:
+		height, round, step := d.varint(), d.varint(), string(d.bytes())
+		step = strings.TrimPrefix(step, "RoundStep")
+
+		//a new height isn't a step the text logs enter
+		if step == "NewHeight" || step == "" {
+			break
+		}
+		entry.Descrip = fmt.Sprintf("enter%s(%d/%d)", step, height, round)
+		entries = append(entries, entry)
+
+	case walTimeout:
+		duration := time.Duration(d.int64())
+		height, round, step := d.varint(), d.varint(), int(d.byte())
+
+		entry.Level, entry.Descrip = "D", "Received tock"
+		entry.Other = map[string]string{"timeout": duration.String(), "height": fmt.Sprint(height), "round": fmt.Sprint(round), "step": ""}
+		if step < len(walSteps) {
+			entry.Other["step"] = walSteps[step]
+		}
+		entries = append(entries, entry)
+
+	case walEndHeight:
+		entry.Descrip = "#ENDHEIGHT"
+		entry.Other["height"] = fmt.Sprint(d.varint())
+		entries = append(entries, entry)
+
+	case walMsgInfo:
+		entries = wal.msgInfo(d, entry)
+
+	default:
+		return nil, fmt.Errorf("unknown message type 0x%02X", kind)
+	}
+
+	return entries, d.err
+}
+
+//msgInfo turns a queued consensus message into a "Receive" from its peer, or into our own signed vote or proposal
+func (wal *walState) msgInfo(d *walDecoder, entry reader.LogEntry) []reader.LogEntry {
+	var entries []reader.LogEntry
+	var msg, key string
+	var height, round, part, total int
+	var vote, address string
+
+	kind := d.byte()
+	switch kind {
+	case walProposal:
+		d.byte()
+		height, round = d.varint(), d.varint()
+		total = d.varint()
+		partsHash := d.bytes()
+		polRound := d.varint()
+		polHash := d.bytes()
+		d.varint()
+		d.bytes()
+		sig := d.signature()
+
+		msg = fmt.Sprintf("[Proposal Proposal{%d/%d %d:%s (%d,%s) /%s.../}]", height, round, total, fingerprint(partsHash), polRound, fingerprint(polHash), fingerprint(sig))
+
+	case walBlockPart:
+		height, round = d.varint(), d.varint()
+		d.byte()
+		part = d.varint()
+		d.bytes()
+		for aunts := d.varint(); aunts > 0 && d.err == nil; aunts-- {
+			d.bytes()
+		}
+
+		msg = fmt.Sprintf("[BlockPart H:%d R:%d P:Part{#%d}]", height, round, part)
+
+	case walVote:
+		d.byte()
+		addr := d.bytes()
+		index := d.varint()
+		height, round = d.varint(), d.varint()
+		voteType := d.byte()
+		hash := d.bytes()
+		d.varint()
+		d.bytes()
+		sig := d.signature()
+
+		typeName := "Prevote"
+		if voteType == 0x02 {
+			typeName = "Precommit"
+		}
+		address = strings.ToUpper(hex.EncodeToString(addr))
+		vote = fmt.Sprintf("Vote{%d:%s %d/%02d/%d(%s) %s /%s.../}", index, fingerprint(addr), height, round, voteType, typeName, fingerprint(hash), fingerprint(sig))
+		msg = "[Vote " + vote + "]"
+
+	default:
+		msg = fmt.Sprintf("[Unknown 0x%02X]", kind)
+		d.pos = len(d.data)
+	}
+
+	//the PeerKey is a go-wire string holding the peer's pubkey already in hex
+	if d.pos < len(d.data) {
+		key = strings.ToUpper(string(d.bytes()))
+	}
+
+	if key != "" {
+		receive := entry
+		receive.Level, receive.Descrip = "D", "Receive"
+		receive.Other = map[string]string{"src": wal.peer(key), "msg": msg}
+		entries = append(entries, receive)
+	} else if kind == walVote {
+		own := entry
+		own.Descrip = "Signed and pushed vote"
+		own.Other = map[string]string{"height": fmt.Sprint(height), "round": fmt.Sprint(round), "vote": vote}
+		entries = append(entries, own)
+
+		if wal.listener == "" {
+			for _, node := range wal.nodes {
+				if node.Pubkey != "" && strings.HasPrefix(address, node.Pubkey) {
+					wal.listener = node.Ip
+				}
+			}
+		}
+	} else if kind == walProposal {
+		own := entry
+		own.Descrip = "Signed proposal"
+		own.Other = map[string]string{"height": fmt.Sprint(height), "round": fmt.Sprint(round), "proposal": strings.TrimSuffix(strings.TrimPrefix(msg, "[Proposal "), "]")}
+		entries = append(entries, own)
+	}
+
+	//with all of the proposal's parts in, its block is complete
+	hr := fmt.Sprintf("%d/%d", height, round)
+	if kind == walProposal || kind == walBlockPart {
+		if wal.parts[hr] == nil {
+			wal.parts[hr] = make(map[int]bool)
+		}
+
+		_, known := wal.totals[hr]
+		seen := kind == walBlockPart && wal.parts[hr][part]
+		if kind == walProposal {
+			wal.totals[hr] = total
+		} else {
+			wal.parts[hr][part] = true
+		}
+
+		//parts can arrive before the proposal they belong to
+		fresh := (kind == walProposal && !known) || (kind == walBlockPart && !seen)
+		if total, ok := wal.totals[hr]; ok && fresh && len(wal.parts[hr]) == total {
+			complete := entry
+			complete.Descrip = "Received complete proposal block"
+			complete.Other = map[string]string{"height": fmt.Sprint(height)}
+			entries = append(entries, complete)
+		}
+	}
+	return entries
+}
+
+//peer writes a peer as the text logs do, eg. "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0}", taking its IP from the
+//node whose pubkey it starts with (or its key, for peers not in nodes.json)
+func (wal *walState) peer(key string) string {
+	short := key
+	if len(short) > 12 {
+		short = short[:12]
+	}
+
+	addr := short
+	for _, node := range wal.nodes {
+		if node.Pubkey != "" && strings.HasPrefix(key, node.Pubkey) {
+			addr = node.Ip + ":46656"
+		}
+	}
+	return "Peer{MConn{" + addr + "} " + short + "}"
+}
+
+//fingerprint writes the first 6 bytes of a hash in hex, as the text logs do (zeros for an empty hash, eg. a nil vote)
+func fingerprint(b []byte) string {
+	fp := make([]byte, 6)
+	copy(fp, b)
+	return strings.ToUpper(hex.EncodeToString(fp))
+}
@@ -0,0 +1,88 @@
+package reader
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//AppBlock is the application's share of committing one height: Execute runs from the node finalizing the commit through
+//the block's txs being delivered ("Executed block"), and Commit from there until the app committed its state ("Committed
+//state"). AppHash is the hash the app returned on commit, and HashChanged whether it differs from the last height's
+type AppBlock struct {
+	Height      int
+	Date        string
+	Time        string
+	Execute     time.Duration
+	Commit      time.Duration
+	ValidTxs    int
+	InvalidTxs  int
+	AppHash     string
+	HashChanged bool
+}
+
+//GetAppBlocks times the application's execution and commit of every block the node committed, in order, from the state
+//module's "Executed block" and "Committed state" entries. Txs are counted from "Executed block", or from the DeliverTx
+//results ("Invalid tx") logged while executing if it's missing
+func GetAppBlocks(entries []LogEntry) ([]AppBlock, error) {
+	var blocks []AppBlock
+	var current *AppBlock
+	var startT, executedT time.Time
+	var lastHash string
+	var delivered, invalid int
+
+	for _, entry := range entries {
+		switch {
+		case strings.HasPrefix(entry.Descrip, "Finalizing commit of block"):
+			height, err := strconv.Atoi(entry.Other["height"])
+			if err != nil {
+				continue
+			}
+
+			startT, err = EntryTime(entry)
+			if err != nil {
+				return blocks, err
+			}
+
+			blocks = append(blocks, AppBlock{Height: height, Date: entry.Date, Time: entry.Time})
+			current = &blocks[len(blocks)-1]
+			executedT = time.Time{}
+			delivered, invalid = leadingInt(strings.TrimPrefix(entry.Descrip, "Finalizing commit of block with ")), 0
+
+		case current == nil:
+			continue
+
+		case entry.Descrip == "Invalid tx" && executedT.IsZero():
+			invalid++
+
+		case entry.Descrip == "Executed block" && executedT.IsZero():
+			var err error
+			executedT, err = EntryTime(entry)
+			if err != nil {
+				return blocks, err
+			}
+			current.Execute = executedT.Sub(startT)
+
+			current.ValidTxs, current.InvalidTxs = delivered-invalid, invalid
+			if valid, err := strconv.Atoi(entry.Other["validTxs"]); err == nil {
+				current.ValidTxs = valid
+			}
+			if invalidTxs, err := strconv.Atoi(entry.Other["invalidTxs"]); err == nil {
+				current.InvalidTxs = invalidTxs
+			}
+
+		case entry.Descrip == "Committed state" && !executedT.IsZero():
+			committedT, err := EntryTime(entry)
+			if err != nil {
+				return blocks, err
+			}
+			current.Commit = committedT.Sub(executedT)
+
+			current.AppHash = entry.Other["hash"]
+			current.HashChanged = current.AppHash != lastHash
+			lastHash = current.AppHash
+			current = nil
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,38 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetAppBlocks(t *testing.T) {
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:01.000", "Finalizing commit of block with 3 txs", "consensus", map[string]string{"height": "1", "hash": "ABCDEF"}},
+		reader.LogEntry{"", "08-14", "00:00:01.001", "Block{}", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:01.050", "Executed block", "state", map[string]string{"height": "1", "validTxs": "2", "invalidTxs": "1"}},
+		reader.LogEntry{"", "08-14", "00:00:01.070", "Committed state", "state", map[string]string{"height": "1", "txs": "3", "hash": "0A0B01"}},
+		reader.LogEntry{"", "08-14", "00:00:02.000", "Finalizing commit of block with 2 txs", "consensus", map[string]string{"height": "2", "hash": "ABCDEF"}},
+		reader.LogEntry{"", "08-14", "00:00:02.100", "Invalid tx", "state", map[string]string{"code": "BadNonce"}},
+		reader.LogEntry{"", "08-14", "00:00:02.500", "Executed block", "state", map[string]string{"height": "2"}},
+		reader.LogEntry{"", "08-14", "00:00:02.510", "Committed state", "state", map[string]string{"height": "2", "txs": "2", "hash": "0A0B01"}},
+		reader.LogEntry{"", "08-14", "00:00:03.000", "Finalizing commit of block with 0 txs", "consensus", map[string]string{"height": "3", "hash": "ABCDEF"}},
+	}
+
+	blocks, err := reader.GetAppBlocks(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []reader.AppBlock{
+		reader.AppBlock{1, "08-14", "00:00:01.000", 50 * time.Millisecond, 20 * time.Millisecond, 2, 1, "0A0B01", true},
+		reader.AppBlock{2, "08-14", "00:00:02.000", 500 * time.Millisecond, 10 * time.Millisecond, 1, 1, "0A0B01", false},
+		reader.AppBlock{3, "08-14", "00:00:03.000", 0, 0, 0, 0, "", false},
+	}
+
+	if !reflect.DeepEqual(exp, blocks) {
+		t.Errorf("expected %v, received %v", exp, blocks)
+	}
+}
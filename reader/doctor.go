@@ -0,0 +1,146 @@
+package reader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Finding describes a consensus anomaly, along with the node's state when it was spotted
+type Finding struct {
+	Severity int
+	Kind     string
+	Date     string
+	Time     string
+	Height   int
+	Round    int
+	Detail   string
+	Status   Status
+}
+
+//Diagnose scans every entry for consensus anomalies and returns them ranked by severity (most severe first).
+//threshold is the number of miliseconds a height may take before it is flagged as slow
+func Diagnose(entries []LogEntry, nodes []Node, threshold int) ([]Finding, error) {
+	var err error
+	var status Status
+	var bpArr []string
+	var findings []Finding
+	var heightStart time.Time
+	var started bool
+	var committed bool
+	var invalid bool
+	var rollover YearRollover
+	var entryT time.Time
+
+	status.Proposal = "No"
+
+	myIP := findMyIP(entries)
+
+	for _, entry := range entries {
+		prev := status
+
+		//one rollover for every entry, so a height running into the new year doesn't go back in time
+		entryT, err = rollover.EntryTime(entry)
+		if err != nil {
+			return findings, err
+		}
+
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return findings, err
+		}
+
+		if isInvalidProposal(entry) {
+			invalid = true
+		}
+
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+			if status.Round == 0 {
+				heightStart = entryT
+				started = true
+				committed = false
+			} else {
+				cause, missing, err := explainRound(prev, invalid, nodes)
+				if err != nil {
+					return findings, err
+				}
+
+				detail := fmt.Sprintf("height %d moved to round %d: %s", status.Height, status.Round, cause)
+				if len(missing) > 0 {
+					detail = detail + " (missing: " + strings.Join(missing, ", ") + ")"
+				}
+				findings = append(findings, newFinding(2, "round change", entry, status, detail))
+			}
+			invalid = false
+		}
+
+		if status.Step == "Precommit" && prev.Step != "Precommit" {
+			received := countVotes(status.PreVotes)
+			if received*3 <= len(nodes)*2 {
+				detail := fmt.Sprintf("entered precommit with %d/%d prevotes", received, len(nodes))
+				findings = append(findings, newFinding(3, "no +2/3 prevotes", entry, status, detail))
+			}
+		}
+
+		if status.Step == "Commit" && prev.Step != "Commit" && started && !committed {
+			committed = true
+
+			elapsed := entryT.Sub(heightStart)
+			if elapsed > time.Duration(threshold)*time.Millisecond {
+				detail := fmt.Sprintf("height %d took %s to commit", status.Height, elapsed)
+				findings = append(findings, newFinding(3, "slow height", entry, status, detail))
+			}
+		}
+
+		if entry.Descrip == "Timed out" && entry.Other["step"] != "RoundStepNewHeight" {
+			detail := fmt.Sprintf("%s timeout fired after %s", entry.Other["step"], entry.Other["dur"])
+			findings = append(findings, newFinding(1, "timeout", entry, status, detail))
+		}
+	}
+
+	//a height that never committed, having changed rounds or sat in its first round past the threshold, is the most
+	//likely cause of a halt
+	if started && !committed {
+		last := entries[len(entries)-1]
+
+		elapsed := entryT.Sub(heightStart)
+		if status.Round > 0 || elapsed > time.Duration(threshold)*time.Millisecond {
+			detail := fmt.Sprintf("height %d never committed after %d rounds (%s)", status.Height, status.Round+1, elapsed)
+			findings = append(findings, newFinding(4, "stalled", last, status, detail))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+
+	return findings, err
+}
+
+//*********************************************************************following functions belong to Diagnose***********************************************
+
+func newFinding(severity int, kind string, entry LogEntry, status Status, detail string) Finding {
+	return Finding{
+		Severity: severity,
+		Kind:     kind,
+		Date:     entry.Date,
+		Time:     entry.Time,
+		Height:   status.Height,
+		Round:    status.Round,
+		Detail:   detail,
+		Status:   copyStatus(status),
+	}
+}
+
+//counts votes marked in a vote array, sent or received, nil or not
+func countVotes(votes []string) int {
+	count := 0
+
+	for _, vote := range votes {
+		if vote != "_" {
+			count++
+		}
+	}
+	return count
+}
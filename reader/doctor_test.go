@@ -0,0 +1,89 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestDiagnose(t *testing.T) {
+	nodes := NODES
+
+	testCases := []struct {
+		entries []reader.LogEntry
+		kinds   []string
+	}{
+		{
+			//stalled height outranks missing prevotes, which outrank round changes and timeouts
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:01.002", "enterPrecommit(1/0). Current: 1/0/RoundStepPrevoteWait", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:02.002", "Timed out", "consensus", map[string]string{"dur": "1s", "height": "1", "round": "0", "step": "RoundStepPrecommitWait"}},
+				reader.LogEntry{"", "08-14", "00:00:02.003", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			[]string{"stalled", "no +2/3 prevotes", "round change", "timeout"},
+		},
+		{
+			//slow heights are flagged; commit timeouts and well-supported precommits are not
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 2/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 2/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "", map[string]string{"msg": "[Vote Vote{2:87708B69426D 2/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "", map[string]string{"msg": "[Vote Vote{3:B7AACD67CE2E 2/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:01.001", "enterPrecommit(2/0). Current: 2/0/RoundStepPrevote", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:05.000", "enterCommit(2/0). Current: 2/0/RoundStepPrecommit", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:06.000", "Timed out", "consensus", map[string]string{"dur": "1s", "height": "2", "round": "0", "step": "RoundStepNewHeight"}},
+			},
+			[]string{"slow height"},
+		},
+		{
+			//a height running into the new year is timed from the year it started in
+			[]reader.LogEntry{
+				reader.LogEntry{"", "12-31", "23:59:58.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "12-31", "23:59:58.000", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "01-01", "00:00:04.000", "enterCommit(2/0). Current: 2/0/RoundStepPrecommit", "consensus", map[string]string{}},
+			},
+			[]string{"slow height"},
+		},
+		{
+			//a height stuck in its first round is stalled once it takes longer than the threshold, but not before
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(3/0). Current: 3/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterPropose(3/0). Current: 3/0/RoundStepNewRound", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:05.000", "Receive", "", map[string]string{"msg": "[NewRoundStep H:3 R:0 S:RoundStepPropose]"}},
+			},
+			[]string{"stalled"},
+		},
+		{
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(3/0). Current: 3/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterPropose(3/0). Current: 3/0/RoundStepNewRound", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "", map[string]string{"msg": "[NewRoundStep H:3 R:0 S:RoundStepPropose]"}},
+			},
+			nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		findings, err := reader.Diagnose(testCase.entries, nodes, 3000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var kinds []string
+		for _, finding := range findings {
+			kinds = append(kinds, finding.Kind)
+		}
+
+		if !reflect.DeepEqual(testCase.kinds, kinds) {
+			t.Errorf("expected %s, received %s", testCase.kinds, kinds)
+		}
+	}
+}
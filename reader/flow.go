@@ -0,0 +1,125 @@
+package reader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Flow is the traffic over one edge of the P2P graph in a window: the messages each end received from the other, and
+//the median delay between a validator signing a vote and the other end receiving it straight from the signer (taken
+//across machines, so clock skew counts). Samples is 0 if no such vote was seen
+type Flow struct {
+	A         string
+	B         string
+	Connected bool
+	AtoB      int
+	BtoA      int
+	Latency   time.Duration
+	Samples   int
+}
+
+//GetFlows weighs each edge of the graph over the window from start to end, by counting what every log received from
+//each peer. Edges up at end are listed, along with any others messages crossed during the window, in nodes.json order
+func GetFlows(topology *Topology, logs [][]LogEntry, nodes []Node, start time.Time, end time.Time) ([]Flow, error) {
+	var flows []Flow
+
+	counts := make(map[[2]string]int)
+	delays := make(map[[2]string][]time.Duration)
+	signed := make(map[string]time.Time)
+
+	//every log's own votes first, so receipts in any log can be matched to them
+	for _, entries := range logs {
+		for _, entry := range entries {
+			if entry.Descrip != "Signed and pushed vote" {
+				continue
+			}
+
+			vote, err := parseVote(entry.Other["vote"])
+			if err != nil {
+				return flows, err
+			}
+
+			signedT, err := EntryTime(entry)
+			if err != nil {
+				return flows, err
+			}
+			signed[voteKey(vote)] = signedT
+		}
+	}
+
+	for _, entries := range logs {
+		me := findName(nodes, findMyIP(entries))
+		if me == "" {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.Descrip != "Receive" {
+				continue
+			}
+
+			entryT, err := EntryTime(entry)
+			if err != nil {
+				return flows, err
+			}
+			if entryT.Before(start) {
+				continue
+			}
+			if entryT.After(end) {
+				break
+			}
+
+			from := findName(nodes, peerIP(entry))
+			if from == "" {
+				continue
+			}
+			counts[[2]string{from, me}]++
+
+			if !strings.Contains(entry.Other["msg"], "Vote Vote") {
+				continue
+			}
+
+			vote, err := parseVote(entry.Other["msg"])
+			if err != nil {
+				return flows, err
+			}
+
+			//only votes that came straight from their signer time the edge
+			signedT, ok := signed[voteKey(vote)]
+			if ok && nodeName(nodes, vote.Index) == from {
+				delays[[2]string{from, me}] = append(delays[[2]string{from, me}], entryT.Sub(signedT))
+			}
+		}
+	}
+
+	for i, a := range topology.Nodes {
+		for _, b := range topology.Nodes[i+1:] {
+			flow := Flow{A: a, B: b, Connected: topology.Connected(a, b, end)}
+			flow.AtoB = counts[[2]string{a, b}]
+			flow.BtoA = counts[[2]string{b, a}]
+
+			if !flow.Connected && flow.AtoB+flow.BtoA == 0 {
+				continue
+			}
+
+			samples := append(append([]time.Duration{}, delays[[2]string{a, b}]...), delays[[2]string{b, a}]...)
+			if len(samples) > 0 {
+				sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+				flow.Latency = samples[len(samples)/2]
+				flow.Samples = len(samples)
+			}
+
+			flows = append(flows, flow)
+		}
+	}
+	return flows, nil
+}
+
+//*********************************************************************following functions belong to GetFlows***********************************************
+
+//voteKey identifies a validator's vote, eg. 1/0/Prevote/3
+func voteKey(vote VoteArrival) string {
+	return fmt.Sprintf("%d/%d/%s/%d", vote.Height, vote.Round, vote.Type, vote.Index)
+}
@@ -0,0 +1,53 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetFlows(t *testing.T) {
+	nodes := NODES
+
+	logs := [][]reader.LogEntry{
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.44.161:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:02.000", "Signed and pushed vote", "consensus", map[string]string{"vote": "Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}"}},
+			reader.LogEntry{"", "08-14", "00:00:02.120", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:03.000", "Receive", "consensus", map[string]string{"msg": "[HasVote VI:2 V:{1/00/1}]", "src": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+		},
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.39.83:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:02.040", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:02.100", "Signed and pushed vote", "consensus", map[string]string{"vote": "Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}"}},
+			reader.LogEntry{"", "08-14", "00:00:05.000", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		},
+	}
+
+	topology, err := reader.GetTopology(logs, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start, _ := reader.ParseTime("08-14", "00:00:00")
+	end, _ := reader.ParseTime("08-14", "00:00:04")
+
+	flows, err := reader.GetFlows(topology, logs, nodes, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []reader.Flow{
+		reader.Flow{nodes[0].Name, nodes[1].Name, true, 1, 2, 40 * time.Millisecond, 2},
+		reader.Flow{nodes[1].Name, nodes[2].Name, true, 0, 0, 0, 0},
+	}
+
+	if !reflect.DeepEqual(exp, flows) {
+		t.Errorf("expected %v, received %v", exp, flows)
+	}
+}
@@ -0,0 +1,69 @@
+package reader
+
+import (
+	"time"
+)
+
+//Follower keeps a node's Status, and its msgs row for the current interval, up to date as entries arrive one at a time
+type Follower struct {
+	Status   Status
+	Msgs     []string
+	Interval string
+	nodes    []Node
+	myIP     string
+	bpArr    []string
+	dur      time.Duration
+	start    time.Time
+	rollover YearRollover
+}
+
+//NewFollower returns a Follower for a log read from its start; msgs rows are kept at intervals of dur, or not at all if dur is 0
+func NewFollower(nodes []Node, dur time.Duration) *Follower {
+	return &Follower{Status: Status{Proposal: "No"}, Msgs: emptyRow(len(nodes)), nodes: nodes, dur: dur}
+}
+
+//Add reads one entry into the Status and msgs row. Should the entry fall past the current interval, the rows of every interval
+//it closes (empty or not) are returned with their time stamps, as GetMessages would print them
+func (follower *Follower) Add(entry LogEntry) ([]string, [][]string, error) {
+	var err error
+	var timeStamps []string
+	var msgArr [][]string
+
+	//the node's IP is only logged once, at startup
+	if follower.myIP == "" && entry.Descrip == "Starting DefaultListener" {
+		follower.myIP = listenerIP(entry)
+	}
+
+	follower.Status, follower.bpArr, err = updateStatus(follower.Status, entry, follower.nodes, follower.myIP, follower.bpArr)
+	if err != nil {
+		return timeStamps, msgArr, err
+	}
+
+	if follower.dur <= 0 {
+		return timeStamps, msgArr, err
+	}
+
+	entryT, err := follower.rollover.EntryTime(entry)
+	if err != nil {
+		return timeStamps, msgArr, err
+	}
+
+	if follower.start.IsZero() {
+		follower.start = entryT.Truncate(follower.dur)
+		follower.Interval = follower.start.Format(timeLayout)
+	}
+
+	//close every interval that's over
+	for !entryT.Before(follower.start.Add(follower.dur)) {
+		timeStamps = append(timeStamps, follower.Interval)
+		msgArr = append(msgArr, follower.Msgs)
+
+		follower.start = follower.start.Add(follower.dur)
+		follower.Interval = follower.start.Format(timeLayout)
+		follower.Msgs = emptyRow(len(follower.nodes))
+	}
+
+	follower.Msgs, err = getMsg(follower.Msgs, entry, follower.nodes, follower.myIP, false)
+
+	return timeStamps, msgArr, err
+}
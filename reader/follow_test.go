@@ -0,0 +1,90 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestFollower(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 4F85D81F23AB out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.005", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.007", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
+	}
+
+	follower := reader.NewFollower(nodes, 2*time.Millisecond)
+
+	var timeStamps []string
+	var msgArr [][]string
+
+	//following entry by entry should match GetStatus at each entry's time
+	for _, entry := range entries {
+		stamps, rows, err := follower.Add(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timeStamps = append(timeStamps, stamps...)
+		msgArr = append(msgArr, rows...)
+
+		exp, _ := reader.GetStatus(entries, nodes, entry.Date, entry.Time)
+		if !reflect.DeepEqual(exp, follower.Status) {
+			t.Errorf("at %s expected %v, received %v", entry.Time, exp, follower.Status)
+		}
+	}
+
+	//closed intervals, then the one still open, should match MessageRows over the same span
+	timeStamps = append(timeStamps, follower.Interval)
+	msgArr = append(msgArr, follower.Msgs)
+
+	expStamps, expArr, err := reader.MessageRows(entries, nodes, 2*time.Millisecond, "08-14", "00:00:00.000", "08-14", "00:00:00.007")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expStamps, timeStamps) || !reflect.DeepEqual(expArr, msgArr) {
+		t.Errorf("expected %v %v, received %v %v", expStamps, expArr, timeStamps, msgArr)
+	}
+}
+
+func TestFollowerNewYear(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "12-31", "23:59:59.500", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "12-31", "23:59:59.700", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 4F85D81F23AB out}"}},
+		reader.LogEntry{"", "01-01", "00:00:00.200", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
+	}
+
+	follower := reader.NewFollower(nodes, time.Second)
+
+	var timeStamps []string
+	var msgArr [][]string
+
+	//intervals keep closing once the log runs into the new year
+	for _, entry := range entries {
+		stamps, rows, err := follower.Add(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timeStamps = append(timeStamps, stamps...)
+		msgArr = append(msgArr, rows...)
+	}
+	timeStamps = append(timeStamps, follower.Interval)
+	msgArr = append(msgArr, follower.Msgs)
+
+	expStamps, expArr, err := reader.MessageRows(entries, nodes, time.Second, "12-31", "23:59:59.000", "01-01", "00:00:00.200")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expStamps) != 2 || !reflect.DeepEqual(expStamps, timeStamps) || !reflect.DeepEqual(expArr, msgArr) {
+		t.Errorf("expected %v %v, received %v %v", expStamps, expArr, timeStamps, msgArr)
+	}
+}
@@ -0,0 +1,124 @@
+package reader
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//HeightRow is one committed height as the heights table shows it: when the node entered commit, the time since it
+//entered the last height's commit (Interval, 0 for the first), the rounds it took, its proposer and txs, how long the
+//node spent in each step (keyed as Status names them, summed over rounds; Commit runs until the next height starts), and
+//whether the node didn't sign a precommit in the round that committed
+type HeightRow struct {
+	Height      int
+	Date        string
+	Commit      string
+	Interval    time.Duration
+	Rounds      int
+	Proposer    string
+	Txs         int
+	Steps       map[string]time.Duration
+	NoPrecommit bool
+}
+
+//HeightSteps are the steps a HeightRow is timed in, in order
+var HeightSteps = []string{"NewRound", "Propose", "Prevote", "Precommit", "Commit"}
+
+//GetHeightRows builds a HeightRow for every height from through to (0 for no bound) the node committed, running the
+//same scan as GetStatus over the entries. Rounds and proposer are as GetHeights finds them. NoPrecommit is only set if
+//the node is in nodes, as its votes can't be told apart otherwise
+func GetHeightRows(entries []LogEntry, nodes []Node, from int, to int) ([]HeightRow, error) {
+	var rows []HeightRow
+	var status Status
+	var bpArr []string
+	var current *HeightRow
+	var step string
+	var stepStart, lastCommit time.Time
+	var err error
+
+	status.Proposal = "No"
+
+	heights, err := GetHeights(entries, nodes)
+	if err != nil {
+		return rows, err
+	}
+
+	committed := make(map[int]Height)
+	for _, height := range heights {
+		committed[height.Height] = height
+	}
+
+	myIP := findMyIP(entries)
+	myIndex := -1
+	for _, node := range nodes {
+		if node.Ip == myIP {
+			myIndex, _ = strconv.Atoi(node.Index)
+		}
+	}
+
+	//a height's row is done when the next height starts, or the log ends
+	finish := func(status Status) {
+		if current == nil || current.Date == "" || (from > 0 && current.Height < from) || (to > 0 && current.Height > to) {
+			return
+		}
+
+		height := committed[current.Height]
+		current.Rounds, current.Proposer = height.Rounds, height.Proposer
+
+		if myIndex >= 0 && myIndex < len(status.PreCommits) {
+			current.NoPrecommit = status.PreCommits[myIndex] != "O" && status.PreCommits[myIndex] != "N"
+		}
+		rows = append(rows, *current)
+	}
+
+	for _, entry := range entries {
+		prev := status
+
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return rows, err
+		}
+
+		if strings.HasPrefix(entry.Descrip, "Finalizing commit of block") && current != nil && entry.Other["height"] == strconv.Itoa(current.Height) {
+			current.Txs = leadingInt(strings.TrimPrefix(entry.Descrip, "Finalizing commit of block with "))
+		}
+
+		if !isStep(entry) {
+			continue
+		}
+
+		entryT, err := EntryTime(entry)
+		if err != nil {
+			return rows, err
+		}
+
+		if current != nil && step != "" {
+			current.Steps[step] += entryT.Sub(stepStart)
+		}
+
+		if current == nil || status.Height != current.Height {
+			finish(prev)
+			current = &HeightRow{Height: status.Height, Steps: make(map[string]time.Duration)}
+		}
+		step, stepStart = status.Step, entryT
+
+		if status.Step == "Commit" && current.Date == "" {
+			current.Date, current.Commit = entry.Date, entry.Time
+			if !lastCommit.IsZero() {
+				current.Interval = entryT.Sub(lastCommit)
+			}
+			lastCommit = entryT
+		}
+	}
+
+	if current != nil && len(entries) > 0 {
+		endT, err := EntryTime(entries[len(entries)-1])
+		if err != nil {
+			return rows, err
+		}
+		current.Steps[step] += endT.Sub(stepStart)
+		finish(status)
+	}
+	return rows, nil
+}
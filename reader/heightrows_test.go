@@ -0,0 +1,63 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetHeightRows(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "enterPropose: Not our turn to propose", "consensus", map[string]string{"proposer": "3D3074F7A7D01071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:03.001", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:03.100", "enterPrecommit(1/0). Current: 1/0/RoundStepPrevote", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:03.200", "Signed and pushed vote", "consensus", map[string]string{"height": "1", "round": "0", "vote": "Vote{4:E40892926ECF 1/00/2(Precommit) 000000000000 /5F5F5F5F5F5F/}"}},
+		reader.LogEntry{"", "08-14", "00:00:04.000", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:04.001", "enterPropose: Our turn to propose", "consensus", map[string]string{"proposer": "E40892926ECF1071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:04.100", "enterPrevote(1/1). Current: 1/1/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:04.200", "enterPrecommit(1/1). Current: 1/1/RoundStepPrevote", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:04.250", "Signed and pushed vote", "consensus", map[string]string{"height": "1", "round": "1", "vote": "Vote{4:E40892926ECF 1/01/2(Precommit) 0E9ED1D3E9A0 /5F5F5F5F5F5F/}"}},
+		reader.LogEntry{"", "08-14", "00:00:04.300", "enterCommit(1/1). Current: 1/1/RoundStepPrecommit", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:04.310", "Finalizing commit of block with 3 txs", "consensus", map[string]string{"height": "1"}},
+		reader.LogEntry{"", "08-14", "00:00:05.300", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.301", "enterPropose: Not our turn to propose", "consensus", map[string]string{"proposer": "2A3A16F15BEE1071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:05.400", "enterPrevote(2/0). Current: 2/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.500", "enterPrecommit(2/0). Current: 2/0/RoundStepPrevote", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.600", "enterCommit(2/0). Current: 2/0/RoundStepPrecommit", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.610", "Finalizing commit of block with 0 txs", "consensus", map[string]string{"height": "2"}},
+		reader.LogEntry{"", "08-14", "00:00:06.600", "enterNewRound(3/0). Current: 3/0/RoundStepNewHeight", "consensus", map[string]string{}},
+	}
+
+	row1 := reader.HeightRow{1, "08-14", "00:00:04.300", 0, 2, nodes[4].Name, 3,
+		map[string]time.Duration{"NewRound": 2 * time.Millisecond, "Propose": 3099 * time.Millisecond, "Prevote": 199 * time.Millisecond, "Precommit": time.Second, "Commit": time.Second}, false}
+	row2 := reader.HeightRow{2, "08-14", "00:00:05.600", 1300 * time.Millisecond, 1, nodes[0].Name, 0,
+		map[string]time.Duration{"NewRound": time.Millisecond, "Propose": 99 * time.Millisecond, "Prevote": 100 * time.Millisecond, "Precommit": 100 * time.Millisecond, "Commit": time.Second}, true}
+
+	testCases := []struct {
+		from int
+		to   int
+		exp  []reader.HeightRow
+	}{
+		{0, 0, []reader.HeightRow{row1, row2}},
+		{2, 0, []reader.HeightRow{row2}},
+		{0, 1, []reader.HeightRow{row1}},
+		{3, 5, nil},
+	}
+
+	for _, testCase := range testCases {
+		rows, err := reader.GetHeightRows(entries, nodes, testCase.from, testCase.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(rows, testCase.exp) {
+			t.Errorf("from %d to %d expected %v, got %v", testCase.from, testCase.to, testCase.exp, rows)
+		}
+	}
+}
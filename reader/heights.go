@@ -0,0 +1,93 @@
+package reader
+
+import (
+	"strings"
+	"time"
+)
+
+//Height summarizes one committed height from a node's perspective
+type Height struct {
+	Height   int
+	Date     string
+	Start    string
+	Commit   string
+	Duration time.Duration
+	Rounds   int
+	Proposer string
+}
+
+//GetHeights summarizes every height the node committed, in order
+func GetHeights(entries []LogEntry, nodes []Node) ([]Height, error) {
+	var err error
+	var heights []Height
+	var current Height
+	var startTime time.Time
+	var proposer string
+	var committed bool
+
+	me := findName(nodes, findMyIP(entries))
+
+	for _, entry := range entries {
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+			height, round, err := heightRound(entry.Descrip)
+			if err != nil {
+				return heights, err
+			}
+
+			if height != current.Height {
+				startTime, err = EntryTime(entry)
+				if err != nil {
+					return heights, err
+				}
+
+				current = Height{Height: height, Date: entry.Date, Start: entry.Time}
+				committed = false
+			}
+			current.Rounds = round + 1
+			proposer = ""
+		}
+
+		if strings.Contains(entry.Descrip, "Our turn to propose") {
+			proposer = me
+		} else if strings.Contains(entry.Descrip, "Not our turn to propose") {
+			proposer = findProposer(nodes, entry.Other["proposer"])
+		}
+
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterCommit") && !committed && current.Height != 0 {
+			commitTime, err := EntryTime(entry)
+			if err != nil {
+				return heights, err
+			}
+
+			committed = true
+			current.Commit = entry.Time
+			current.Duration = commitTime.Sub(startTime)
+			current.Proposer = proposer
+
+			heights = append(heights, current)
+		}
+	}
+	return heights, err
+}
+
+//*********************************************************************following functions belong to GetHeights***********************************************
+
+//findName finds the name of the node with the given IP (the node's own, or a peer's), or "" if none has it
+func findName(nodes []Node, ip string) string {
+	for _, node := range nodes {
+		if node.Ip == ip {
+			return node.Name
+		}
+	}
+	return ""
+}
+
+//findProposer names the node whose pubkey starts the proposer's address, or returns the address if none does
+func findProposer(nodes []Node, address string) string {
+	for _, node := range nodes {
+		if node.Pubkey != "" && strings.HasPrefix(strings.ToUpper(address), node.Pubkey) {
+			return node.Name
+		}
+	}
+	return address
+}
@@ -0,0 +1,40 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetHeights(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "enterPropose: Not our turn to propose", "consensus", map[string]string{"proposer": "3D3074F7A7D01071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:03.000", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:03.001", "enterPropose: Our turn to propose", "consensus", map[string]string{"proposer": "E40892926ECF1071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:04.500", "enterCommit(1/1). Current: 1/1/RoundStepPrecommit", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.500", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:05.501", "enterPropose: Not our turn to propose", "consensus", map[string]string{"proposer": "2A3A16F15BEE1071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:06.000", "enterCommit(2/0). Current: 2/0/RoundStepPrecommit", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:07.000", "enterNewRound(3/0). Current: 3/0/RoundStepNewHeight", "consensus", map[string]string{}},
+	}
+
+	expected := []reader.Height{
+		{Height: 1, Date: "08-14", Start: "00:00:00.000", Commit: "00:00:04.500", Duration: 4500 * time.Millisecond, Rounds: 2, Proposer: nodes[4].Name},
+		{Height: 2, Date: "08-14", Start: "00:00:05.500", Commit: "00:00:06.000", Duration: 500 * time.Millisecond, Rounds: 1, Proposer: nodes[0].Name},
+	}
+
+	heights, err := reader.GetHeights(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, heights) {
+		t.Errorf("expected %v, received %v", expected, heights)
+	}
+}
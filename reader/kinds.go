@@ -0,0 +1,112 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//MsgKinds lists the message kinds found in "Receive" entries, in the order they're displayed
+var MsgKinds = []string{"NewRoundStep", "CommitStep", "Proposal", "ProposalPOL", "BlockPart", "Vote", "HasVote", "VoteSetMaj23", "VoteSetBits", "Tx"}
+
+//MsgCounts holds, for one interval, the number of messages of each kind received from each node (indexed as in
+//nodes.json), and the same counts split by the channel (chId) they arrived on
+type MsgCounts struct {
+	Time     string
+	Counts   map[string][]int
+	Channels map[string]map[string][]int
+}
+
+//GetMessageKinds breaks down received messages by kind and channel over intervals of length dur; only the given kinds
+//are counted, and any kind not in MsgKinds is an error
+func GetMessageKinds(entries []LogEntry, nodes []Node, dur time.Duration, stD string, stT string, enD string, enT string, kinds []string) ([]MsgCounts, error) {
+	var countArr []MsgCounts
+
+	for _, kind := range kinds {
+		known := false
+		for _, msgKind := range MsgKinds {
+			if kind == msgKind {
+				known = true
+			}
+		}
+		if !known {
+			return countArr, fmt.Errorf("unknown msg kind %q (kinds: %s)", kind, strings.Join(MsgKinds, ", "))
+		}
+	}
+
+	counts := newMsgCounts(kinds, len(nodes))
+
+	read := func(entry LogEntry) error {
+		if entry.Descrip != "Receive" {
+			return nil
+		}
+
+		kind := msgKind(entry)
+		if _, ok := counts.Counts[kind]; !ok {
+			return nil
+		}
+
+		index, err := nodeIndex(peerIP(entry), nodes)
+		if err != nil || index < 0 {
+			return err
+		}
+
+		counts.Counts[kind][index]++
+
+		ch := entry.Other["chId"]
+		if _, ok := counts.Channels[kind][ch]; !ok {
+			counts.Channels[kind][ch] = make([]int, len(nodes))
+		}
+		counts.Channels[kind][ch][index]++
+
+		return nil
+	}
+
+	flush := func(timeStamp string) {
+		counts.Time = timeStamp
+		countArr = append(countArr, counts)
+		counts = newMsgCounts(kinds, len(nodes))
+	}
+
+	err := walkIntervals(entries, dur, stD, stT, enD, enT, read, flush)
+
+	return countArr, err
+}
+
+//*********************************************************************following functions belong to GetMessageKinds***********************************************
+
+func newMsgCounts(kinds []string, lenNodes int) MsgCounts {
+	counts := MsgCounts{Counts: map[string][]int{}, Channels: map[string]map[string][]int{}}
+
+	for _, kind := range kinds {
+		counts.Counts[kind] = make([]int, lenNodes)
+		counts.Channels[kind] = map[string][]int{}
+	}
+	return counts
+}
+
+//msgKind names the kind of message in a "Receive" entry (eg. "[Vote Vote{...}]" is a Vote); mempool TxMessages are Tx
+func msgKind(entry LogEntry) string {
+	msg := strings.TrimPrefix(entry.Other["msg"], "[")
+	kind := strings.Fields(msg)
+
+	if len(kind) == 0 {
+		return ""
+	}
+
+	if kind[0] == "TxMessage" {
+		return "Tx"
+	}
+	return strings.TrimSuffix(kind[0], "]")
+}
+
+//nodeIndex finds the bit array index of the node with the given ip, or -1 if it isn't in nodes
+func nodeIndex(ip string, nodes []Node) (int, error) {
+	for _, node := range nodes {
+		if node.Ip == ip {
+			return strconv.Atoi(node.Index)
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,58 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetMessageKinds(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}", "chId": "34", "msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}", "chId": "35", "msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}", "chId": "32", "msg": "[HasVote VI:0 V:{1/00/1(Prevote)}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}", "chId": "32", "msg": "[HasVote VI:2 V:{1/00/1(Prevote)}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}", "chId": "34", "msg": "[Vote Vote{0:2A3A16F15BEE 1/00/2(Precommit) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "mempool",
+			map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}", "chId": "48", "msg": "[TxMessage 0A0B0C]"}},
+	}
+
+	countArr, err := reader.GetMessageKinds(entries, nodes, 2*time.Millisecond, "08-14", "00:00:00.000", "08-14", "00:00:00.003", []string{"Vote", "HasVote"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string][]int{
+		{"Vote": {1, 1, 0, 0, 0}, "HasVote": {0, 2, 0, 0, 0}},
+		{"Vote": {1, 0, 0, 0, 0}, "HasVote": {0, 0, 0, 0, 0}},
+	}
+
+	var received []map[string][]int
+	for _, counts := range countArr {
+		received = append(received, counts.Counts)
+	}
+
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("expected %v, received %v", expected, received)
+	}
+
+	expChannels := map[string]map[string][]int{"Vote": {"34": {1, 0, 0, 0, 0}, "35": {0, 1, 0, 0, 0}}, "HasVote": {"32": {0, 2, 0, 0, 0}}}
+	if !reflect.DeepEqual(expChannels, countArr[0].Channels) {
+		t.Errorf("expected %v, received %v", expChannels, countArr[0].Channels)
+	}
+
+	_, err = reader.GetMessageKinds(entries, nodes, 2*time.Millisecond, "08-14", "00:00:00.000", "08-14", "00:00:00.003", []string{"Vote", "Votes"})
+	if err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
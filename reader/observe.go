@@ -0,0 +1,110 @@
+package reader
+
+import (
+	"strings"
+	"time"
+)
+
+//Observation is one sample an entry yields for a metrics exporter
+type Observation struct {
+	Metric string
+	Name   string
+	Type   string
+	Value  float64
+}
+
+//observation metrics: Value is in seconds for durations, and otherwise a count
+const (
+	ObserveHeightDuration  = "height duration"
+	ObserveRounds          = "rounds"
+	ObserveProposalLatency = "proposal latency"
+	ObserveVote            = "vote"
+	ObserveMsg             = "msg"
+)
+
+//Observer follows a node's Status and derives observations from entries as they arrive one at a time:
+//a height's duration (entering its first round to committing) and rounds once it commits, how long a proposal took to
+//arrive after the node entered its round, every distinct vote received (Name = validator, Type = Prevote or Precommit;
+//copies gossiped by other peers aren't counted again), and every msg received (Name = peer)
+type Observer struct {
+	Follower    *Follower
+	nodes       []Node
+	heightStart time.Time
+	roundStart  time.Time
+	committed   bool
+	votes       map[string]VoteArrival
+}
+
+//NewObserver returns an Observer for a log read from its start
+func NewObserver(nodes []Node) *Observer {
+	return &Observer{Follower: NewFollower(nodes, 0), nodes: nodes, votes: make(map[string]VoteArrival)}
+}
+
+//Add reads one entry, returning whatever it observed
+func (observer *Observer) Add(entry LogEntry) ([]Observation, error) {
+	var observations []Observation
+
+	prev := observer.Follower.Status
+
+	_, _, err := observer.Follower.Add(entry)
+	if err != nil {
+		return observations, err
+	}
+	status := observer.Follower.Status
+
+	entryT, err := EntryTime(entry)
+	if err != nil {
+		return observations, err
+	}
+
+	if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+		if status.Height != prev.Height || observer.heightStart.IsZero() {
+			observer.heightStart = entryT
+			observer.committed = false
+
+			//votes for past heights won't be counted anyway, so stop remembering them
+			for key, vote := range observer.votes {
+				if vote.Height < status.Height {
+					delete(observer.votes, key)
+				}
+			}
+		}
+		observer.roundStart = entryT
+	}
+
+	if isStep(entry) && strings.Contains(entry.Descrip, "enterCommit") && !observer.committed && !observer.heightStart.IsZero() {
+		observer.committed = true
+		observations = append(observations,
+			Observation{Metric: ObserveHeightDuration, Value: entryT.Sub(observer.heightStart).Seconds()},
+			Observation{Metric: ObserveRounds, Value: float64(status.Round + 1)})
+	}
+
+	if status.Proposal == "Yes" && prev.Proposal != "Yes" && !observer.roundStart.IsZero() {
+		observations = append(observations, Observation{Metric: ObserveProposalLatency, Value: entryT.Sub(observer.roundStart).Seconds()})
+	}
+
+	if entry.Descrip != "Receive" {
+		return observations, err
+	}
+
+	for _, node := range observer.nodes {
+		if node.Ip == peerIP(entry) {
+			observations = append(observations, Observation{Metric: ObserveMsg, Name: node.Name, Value: 1})
+			break
+		}
+	}
+
+	if strings.Contains(entry.Other["msg"], "Vote Vote") {
+		vote, err := parseVote(entry.Other["msg"])
+		if err != nil {
+			return observations, err
+		}
+
+		if _, ok := observer.votes[voteKey(vote)]; !ok {
+			observer.votes[voteKey(vote)] = vote
+			observations = append(observations, Observation{Metric: ObserveVote, Name: nodeName(observer.nodes, vote.Index), Type: vote.Type, Value: 1})
+		}
+	}
+
+	return observations, err
+}
@@ -0,0 +1,56 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestObserver(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.250", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+		reader.LogEntry{"", "08-14", "00:00:00.300", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 4F85D81F23AB out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.400", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+		reader.LogEntry{"", "08-14", "00:00:01.000", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:01.500", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+		reader.LogEntry{"", "08-14", "00:00:02.000", "enterCommit(1/1). Current: 1/1/RoundStepPrecommit", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:02.100", "enterCommit(1/1). Current: 1/1/RoundStepCommit", "consensus", map[string]string{}},
+	}
+
+	exp := []reader.Observation{
+		reader.Observation{reader.ObserveProposalLatency, "", "", 0.25},
+		reader.Observation{reader.ObserveMsg, nodes[1].Name, "", 1},
+		reader.Observation{reader.ObserveVote, nodes[1].Name, "Prevote", 1},
+		//the same vote gossiped by another peer is a msg from that peer, but not another vote
+		reader.Observation{reader.ObserveMsg, nodes[0].Name, "", 1},
+		//latency is measured from the start of the round the proposal arrived in
+		reader.Observation{reader.ObserveProposalLatency, "", "", 0.5},
+		//a height is only observed once, when it first commits
+		reader.Observation{reader.ObserveHeightDuration, "", "", 2},
+		reader.Observation{reader.ObserveRounds, "", "", 2},
+	}
+
+	observer := reader.NewObserver(nodes)
+
+	var observations []reader.Observation
+	for _, entry := range entries {
+		observed, err := observer.Add(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		observations = append(observations, observed...)
+	}
+
+	if !reflect.DeepEqual(exp, observations) {
+		t.Errorf("expected %v, received %v", exp, observations)
+	}
+
+	if status := observer.Follower.Status; status.Height != 1 || status.Round != 1 || status.Step != "Commit" {
+		t.Errorf("expected 1/1/Commit, received %d/%d/%s", status.Height, status.Round, status.Step)
+	}
+}
@@ -0,0 +1,168 @@
+package reader
+
+import (
+	"time"
+)
+
+//PeerSet is a node's peers over time, as rebuilt from its p2p entries
+type PeerSet struct {
+	Conns    []PeerConn
+	Failures []PeerRecord
+	Flapping []Flap
+	Gaps     []ValidatorGap
+}
+
+//PeerConn is one connection to a peer, from when it was added until it was stopped. EndDate and EndTime are empty for
+//connections still up when the log ends. Reason is the error the peer was stopped for, or "stopped" if none was given
+type PeerConn struct {
+	Name       string
+	Ip         string
+	Direction  string
+	Persistent bool
+	Validator  bool
+	StartDate  string
+	StartTime  string
+	EndDate    string
+	EndTime    string
+	Reason     string
+}
+
+//Flap is a peer that was disconnected at least flapCount times within flapWindow, starting at Date Time
+type Flap struct {
+	Name        string
+	Ip          string
+	Disconnects int
+	Date        string
+	Time        string
+}
+
+//ValidatorGap is a period when the node was connected to fewer than the minimum number of validators; Connected is the
+//fewest it had. EndDate and EndTime are empty if the gap hadn't closed when the log ended
+type ValidatorGap struct {
+	StartDate string
+	StartTime string
+	EndDate   string
+	EndTime   string
+	Connected int
+}
+
+//GetPeers rebuilds a node's peer set from its p2p entries: each connection, with its direction, whether the peer is a
+//validator (in nodes.json) and persistent (the node reconnects to it), and why it ended, along with failed dials. Peers
+//disconnected flapCount or more times within flapWindow are flagged, as are periods, from the first peer event on, when
+//the node was connected to fewer than minValidators validators
+func GetPeers(entries []LogEntry, nodes []Node, minValidators int, flapCount int, flapWindow time.Duration) (PeerSet, error) {
+	var peers PeerSet
+
+	open := make(map[string]int)
+	persistent := make(map[string]bool)
+	disconnects := make(map[string][]time.Time)
+	var order []string
+
+	validators := 0
+	tracking := false
+	gap := -1
+
+	for line, entry := range entries {
+		if !isPeerEvent(entry) {
+			continue
+		}
+
+		record := peerRecord(line, entry, nodes)
+		if record.Ip == "" {
+			continue
+		}
+
+		switch entry.Descrip {
+		case "Added peer":
+			if _, ok := open[record.Ip]; ok {
+				continue
+			}
+			open[record.Ip] = len(peers.Conns)
+			peers.Conns = append(peers.Conns, PeerConn{record.Name, record.Ip, record.Direction, false, record.Name != "", entry.Date, entry.Time, "", "", ""})
+			if record.Name != "" {
+				validators++
+			}
+
+		case "Stopping peer for error", "Stopping peer", "Stopping Peer":
+			//a peer stopped for an error is logged stopping again by its service, which is no new disconnect
+			i, ok := open[record.Ip]
+			if !ok {
+				continue
+			}
+			delete(open, record.Ip)
+
+			conn := &peers.Conns[i]
+			conn.EndDate, conn.EndTime, conn.Reason = entry.Date, entry.Time, record.Err
+			if conn.Reason == "" {
+				conn.Reason = "stopped"
+			}
+			if conn.Validator {
+				validators--
+			}
+
+			entryT, err := EntryTime(entry)
+			if err != nil {
+				return peers, err
+			}
+			if len(disconnects[record.Ip]) == 0 {
+				order = append(order, record.Ip)
+			}
+			disconnects[record.Ip] = append(disconnects[record.Ip], entryT)
+
+		case "Reconnecting to peer", "Failed to reconnect to peer. Giving up":
+			persistent[record.Ip] = true
+			if entry.Descrip != "Reconnecting to peer" {
+				peers.Failures = append(peers.Failures, record)
+			}
+
+		case "Error dialing peer":
+			peers.Failures = append(peers.Failures, record)
+
+		default:
+			continue
+		}
+
+		tracking = true
+
+		if validators < minValidators && gap < 0 {
+			gap = len(peers.Gaps)
+			peers.Gaps = append(peers.Gaps, ValidatorGap{entry.Date, entry.Time, "", "", validators})
+		} else if validators < minValidators && validators < peers.Gaps[gap].Connected {
+			peers.Gaps[gap].Connected = validators
+		} else if validators >= minValidators && gap >= 0 {
+			peers.Gaps[gap].EndDate, peers.Gaps[gap].EndTime = entry.Date, entry.Time
+			gap = -1
+		}
+	}
+
+	if !tracking {
+		return peers, nil
+	}
+
+	for i := range peers.Conns {
+		peers.Conns[i].Persistent = persistent[peers.Conns[i].Ip]
+	}
+
+	for _, ip := range order {
+		if start, ok := flapStart(disconnects[ip], flapCount, flapWindow); ok {
+			peers.Flapping = append(peers.Flapping, Flap{findName(nodes, ip), ip, len(disconnects[ip]), start.Format("01-02"), start.Format("15:04:05.000")})
+		}
+	}
+	return peers, nil
+}
+
+//*********************************************************************following functions belong to GetPeers***********************************************
+
+//flapStart finds the first time count disconnects fall within window of each other
+func flapStart(times []time.Time, count int, window time.Duration) (time.Time, bool) {
+	if count < 1 {
+		return time.Time{}, false
+	}
+
+	for i := 0; i+count-1 < len(times); i++ {
+		if times[i+count-1].Sub(times[i]) <= window {
+			return times[i], true
+		}
+	}
+	return time.Time{}, false
+}
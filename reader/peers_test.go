@@ -0,0 +1,56 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetPeers(t *testing.T) {
+	nodes := NODES
+
+	peer1 := "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"
+	peer2 := "Peer{MConn{172.31.36.95:46656} 1B4F7B8A8D1A in}"
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": peer1}},
+		reader.LogEntry{"", "08-14", "00:00:02.000", "Added peer", "p2p", map[string]string{"peer": peer2}},
+		reader.LogEntry{"", "08-14", "00:00:03.000", "Stopping peer for error", "p2p", map[string]string{"peer": peer1, "err": "EOF"}},
+		reader.LogEntry{"", "08-14", "00:00:03.001", "Stopping Peer", "p2p", map[string]string{"impl": peer1}},
+		reader.LogEntry{"", "08-14", "00:00:03.002", "Reconnecting to peer", "p2p", map[string]string{"peer": peer1}},
+		reader.LogEntry{"", "08-14", "00:00:04.000", "Added peer", "p2p", map[string]string{"peer": peer1}},
+		reader.LogEntry{"", "08-14", "00:00:05.000", "Stopping peer for error", "p2p", map[string]string{"peer": peer1, "err": "EOF"}},
+		reader.LogEntry{"", "08-14", "00:00:06.000", "Error dialing peer", "p2p", map[string]string{"address": "172.31.39.83:46656", "err": "dial tcp: connection refused"}},
+	}
+
+	peers, err := reader.GetPeers(entries, nodes, 2, 2, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := reader.PeerSet{
+		Conns: []reader.PeerConn{
+			reader.PeerConn{nodes[1].Name, "172.31.39.83", "out", true, true, "08-14", "00:00:01.000", "08-14", "00:00:03.000", "EOF"},
+			reader.PeerConn{nodes[2].Name, "172.31.36.95", "in", false, true, "08-14", "00:00:02.000", "", "", ""},
+			reader.PeerConn{nodes[1].Name, "172.31.39.83", "out", true, true, "08-14", "00:00:04.000", "08-14", "00:00:05.000", "EOF"},
+		},
+		Failures: []reader.PeerRecord{
+			reader.PeerRecord{8, "08-14", "00:00:06.000", "Error dialing peer", nodes[1].Name, "172.31.39.83", "", "dial tcp: connection refused"},
+		},
+		Flapping: []reader.Flap{
+			reader.Flap{nodes[1].Name, "172.31.39.83", 2, "08-14", "00:00:03.000"},
+		},
+		Gaps: []reader.ValidatorGap{
+			reader.ValidatorGap{"08-14", "00:00:01.000", "08-14", "00:00:02.000", 1},
+			reader.ValidatorGap{"08-14", "00:00:03.000", "08-14", "00:00:04.000", 1},
+			reader.ValidatorGap{"08-14", "00:00:05.000", "", "", 1},
+		},
+	}
+
+	if !reflect.DeepEqual(exp, peers) {
+		t.Errorf("expected %v, received %v", exp, peers)
+	}
+}
@@ -0,0 +1,420 @@
+package reader
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Query is a parsed filter expression over entries, eg. module=consensus AND height>=100 AND descrip~"enterPrevote" AND peer=node3
+//
+//Comparisons are field op value, joined with AND, OR, NOT and parentheses. Fields are level, date, time, descrip, module,
+//peer (the node an entry was received from or is about), or any other key in the entry. Ops are = != < <= > >= ~ (regex
+//match) and !~. Known numeric keys compare as numbers; height and round also read an entry's step (eg. enterPrevote(100/0)).
+//A value naming a node in nodes.json also matches that node's IP, pubkey or index
+type Query struct {
+	root  queryNode
+	nodes []Node
+}
+
+//numeric keys compare as numbers
+var numericKeys = map[string]bool{"height": true, "round": true, "chId": true, "newHeight": true, "oldHeight": true, "numTxs": true}
+
+type queryNode interface {
+	match(query *Query, entry LogEntry) bool
+}
+
+type queryAnd struct{ left, right queryNode }
+type queryOr struct{ left, right queryNode }
+type queryNot struct{ node queryNode }
+
+type queryCmp struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (node queryAnd) match(query *Query, entry LogEntry) bool {
+	return node.left.match(query, entry) && node.right.match(query, entry)
+}
+
+func (node queryOr) match(query *Query, entry LogEntry) bool {
+	return node.left.match(query, entry) || node.right.match(query, entry)
+}
+
+func (node queryNot) match(query *Query, entry LogEntry) bool {
+	return !node.node.match(query, entry)
+}
+
+//ParseQuery parses a filter expression (see Query); nodes give names to match against
+func ParseQuery(expr string, nodes []Node) (*Query, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &queryParser{tokens: tokens}
+
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", tokens[parser.pos].text)
+	}
+
+	return &Query{root: root, nodes: nodes}, nil
+}
+
+//Match reports whether the entry satisfies the query
+func (query *Query) Match(entry LogEntry) bool {
+	return query.root.match(query, entry)
+}
+
+//QueryEntries returns the entries from stD stT through enD enT (to whatever precision they're given in) that match the
+//query. An empty start or end leaves that side of the range open
+func QueryEntries(entries []LogEntry, query *Query, stD string, stT string, enD string, enT string) ([]LogEntry, error) {
+	var matches []LogEntry
+	var start, end time.Time
+	var rollover YearRollover
+	var firstMonth time.Month
+
+	hasStart, hasEnd := stD != "", enD != ""
+
+	if len(entries) > 0 && (hasStart || hasEnd) {
+		first, err := EntryTime(entries[0])
+		if err != nil {
+			return matches, err
+		}
+		firstMonth = first.Month()
+	}
+
+	//a log that starts in a later month than a time given must have run into the next year by then
+	inLogYear := func(bound time.Time) time.Time {
+		if firstMonth > bound.Month() {
+			return bound.AddDate(1, 0, 0)
+		}
+		return bound
+	}
+
+	if hasStart {
+		bound, _, err := ParseBound(stD, stT)
+		if err != nil {
+			return matches, err
+		}
+		start = inLogYear(bound)
+	}
+
+	if hasEnd {
+		bound, precision, err := ParseBound(enD, enT)
+		if err != nil {
+			return matches, err
+		}
+		end = inLogYear(bound).Add(precision)
+	}
+
+	if hasStart && hasEnd && end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
+
+	for _, entry := range entries {
+		if hasStart || hasEnd {
+			entryT, err := rollover.EntryTime(entry)
+			if err != nil {
+				return matches, err
+			}
+
+			if hasStart && entryT.Before(start) {
+				continue
+			}
+
+			if hasEnd && !entryT.Before(end) {
+				break
+			}
+		}
+
+		if query.Match(entry) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches, nil
+}
+
+//*********************************************************************following functions belong to Query***********************************************
+
+func (cmp queryCmp) match(query *Query, entry LogEntry) bool {
+	value, ok := fieldValue(entry, cmp.field, query.nodes)
+
+	switch cmp.op {
+	case "~":
+		return ok && cmp.re.MatchString(value)
+	case "!~":
+		return !ok || !cmp.re.MatchString(value)
+	case "!=":
+		return !ok || !cmp.equal(value, query.nodes)
+	case "=":
+		return ok && cmp.equal(value, query.nodes)
+	}
+
+	if !ok {
+		return false
+	}
+
+	order := strings.Compare(value, cmp.value)
+
+	a, errA := strconv.ParseFloat(value, 64)
+	b, errB := strconv.ParseFloat(cmp.value, 64)
+	if numericKeys[cmp.field] && errA == nil && errB == nil {
+		order = 0
+		if a < b {
+			order = -1
+		} else if a > b {
+			order = 1
+		}
+	}
+
+	switch cmp.op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	}
+	return order >= 0
+}
+
+//equal compares as numbers for numeric keys, and takes a node's name to mean its IP, pubkey or index as well
+func (cmp queryCmp) equal(value string, nodes []Node) bool {
+	if value == cmp.value {
+		return true
+	}
+
+	if numericKeys[cmp.field] {
+		a, errA := strconv.ParseFloat(value, 64)
+		b, errB := strconv.ParseFloat(cmp.value, 64)
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+
+	for _, node := range nodes {
+		if node.Name != cmp.value {
+			continue
+		}
+
+		//a peer as logged, an address or a listener, eg. impl=Listener(@172.31.32.72:46656), compared by its whole IP
+		ip, _ := parsePeer(strings.TrimPrefix(value, "Listener(@"))
+		if (node.Ip != "" && ip == node.Ip) || (node.Pubkey != "" && strings.HasPrefix(strings.ToUpper(value), node.Pubkey)) {
+			return true
+		}
+
+		//a bare index, eg. validator=node3
+		if node.Index == value {
+			return true
+		}
+	}
+	return false
+}
+
+//fieldValue reads a field from an entry, reporting whether the entry has it at all
+func fieldValue(entry LogEntry, field string, nodes []Node) (string, bool) {
+	switch field {
+	case "level":
+		return entry.Level, true
+	case "date":
+		return entry.Date, true
+	case "time":
+		return entry.Time, true
+	case "descrip":
+		return entry.Descrip, true
+	case "module":
+		return entry.Module, true
+	case "peer":
+		ip := peerIP(entry)
+		if ip == "" {
+			ip, _ = parsePeer(entry.Other["peer"])
+		}
+		if ip == "" {
+			return "", false
+		}
+
+		if name := findName(nodes, ip); name != "" {
+			return name, true
+		}
+		return ip, true
+	}
+
+	value, ok := entry.Other[field]
+	if ok {
+		return value, true
+	}
+
+	//steps carry their height and round in their descrip
+	if (field == "height" || field == "round") && isStep(entry) {
+		height, round, err := heightRound(entry.Descrip)
+		if err != nil {
+			return "", false
+		}
+		if field == "height" {
+			return strconv.Itoa(height), true
+		}
+		return strconv.Itoa(round), true
+	}
+	return "", false
+}
+
+//*********************************************************************lexing and parsing***********************************************
+
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+//lexQuery splits an expression into words, quoted strings, ops and parentheses
+func lexQuery(expr string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, queryToken{text: string(c)})
+			i++
+
+		case c == '"':
+			end := i + 1
+			var text []byte
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				//only quotes need escaping, so regexes keep their backslashes
+				if expr[end] == '\\' && end+1 < len(expr) && expr[end+1] == '"' {
+					end++
+				}
+				text = append(text, expr[end])
+			}
+			if end >= len(expr) {
+				return tokens, fmt.Errorf("unterminated string in query")
+			}
+			tokens = append(tokens, queryToken{text: string(text), quoted: true})
+			i = end + 1
+
+		case strings.ContainsRune("=!<>~", rune(c)):
+			op := string(c)
+			if i+1 < len(expr) {
+				switch op + string(expr[i+1]) {
+				case "!=", "!~", "<=", ">=":
+					op += string(expr[i+1])
+				}
+			}
+			if op == "!" {
+				return tokens, fmt.Errorf("unknown op ! in query")
+			}
+			tokens = append(tokens, queryToken{text: op})
+			i += len(op)
+
+		default:
+			end := i
+			for end < len(expr) && !strings.ContainsRune(" \t\n()\"=!<>~", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: expr[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+//keyword reports whether the next token is the given (unquoted, case insensitive) keyword, consuming it if so
+func (parser *queryParser) keyword(word string) bool {
+	if parser.pos < len(parser.tokens) && !parser.tokens[parser.pos].quoted && strings.EqualFold(parser.tokens[parser.pos].text, word) {
+		parser.pos++
+		return true
+	}
+	return false
+}
+
+func (parser *queryParser) parseOr() (queryNode, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.keyword("OR") {
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (parser *queryParser) parseAnd() (queryNode, error) {
+	left, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.keyword("AND") {
+		right, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (parser *queryParser) parseNot() (queryNode, error) {
+	if parser.keyword("NOT") {
+		node, err := parser.parseNot()
+		return queryNot{node}, err
+	}
+
+	if parser.keyword("(") {
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !parser.keyword(")") {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		return node, nil
+	}
+
+	if parser.pos+3 > len(parser.tokens) {
+		return nil, fmt.Errorf("incomplete comparison at end of query")
+	}
+
+	field, op, value := parser.tokens[parser.pos], parser.tokens[parser.pos+1], parser.tokens[parser.pos+2]
+	parser.pos += 3
+
+	if field.quoted || field.text == "(" || field.text == ")" {
+		return nil, fmt.Errorf("expected a field, received %q", field.text)
+	}
+
+	switch op.text {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return queryCmp{field: field.text, op: op.text, value: value.text}, nil
+	case "~", "!~":
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, err
+		}
+		return queryCmp{field: field.text, op: op.text, value: value.text, re: re}, nil
+	}
+	return nil, fmt.Errorf("expected an op after %s, received %q", field.text, op.text)
+}
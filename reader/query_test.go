@@ -0,0 +1,114 @@
+package reader_test
+
+import (
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestQuery(t *testing.T) {
+	nodes := []reader.Node{
+		reader.Node{"node1", "172.31.39.83", "3D3074F7A7D0", "1"},
+		reader.Node{"node3", "172.31.46.4", "B7AACD67CE2E", "3"},
+	}
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"I", "08-14", "00:00:00.000", "enterPrevote(100/0). Current: 100/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"D", "08-14", "00:00:01.000", "Receive", "consensus", map[string]string{"chId": "32", "msg": "[NewRoundStep H:99 R:0 S:RoundStepNewHeight LCR:-1]", "src": "Peer{MConn{172.31.46.4:46656} B7AACD67CE2E out}"}},
+		reader.LogEntry{"I", "08-14", "00:00:02.000", "enterPropose: Not our turn to propose", "consensus", map[string]string{"height": "9", "proposer": "3D3074F7A7D01071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"I", "08-14", "00:00:03.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 in}"}},
+		reader.LogEntry{"D", "08-14", "00:00:04.000", "Receive", "consensus", map[string]string{"chId": "34", "src": "Peer{MConn{172.31.46.40:46656} 9F1A2B3C4D5E out}"}},
+	}
+
+	//each query lists the indexes of the entries it should match
+	testCases := []struct {
+		query   string
+		matches []int
+	}{
+		{`module=consensus AND height>=100 AND descrip~"enterPrevote"`, []int{0}},
+		//heights compare as numbers, not strings
+		{`height<10`, []int{2}},
+		//node3's IP is only part of 172.31.46.40
+		{`peer=node3`, []int{1}},
+		{`src=node3`, []int{1}},
+		{`peer=node1 OR proposer=node1`, []int{2, 3}},
+		{`NOT (module=consensus) AND level=I`, []int{3}},
+		{`msg~"H:\d+ R:0" AND chId!=33`, []int{1}},
+		{`src!~B7AACD67`, []int{0, 2, 3, 4}},
+	}
+
+	for _, testCase := range testCases {
+		query, err := reader.ParseQuery(testCase.query, nodes)
+		if err != nil {
+			t.Fatal(testCase.query, err)
+		}
+
+		var matches []int
+		for i, entry := range entries {
+			if query.Match(entry) {
+				matches = append(matches, i)
+			}
+		}
+
+		if len(matches) != len(testCase.matches) {
+			t.Errorf("%s: expected %v, received %v", testCase.query, testCase.matches, matches)
+			continue
+		}
+		for i := range matches {
+			if matches[i] != testCase.matches[i] {
+				t.Errorf("%s: expected %v, received %v", testCase.query, testCase.matches, matches)
+				break
+			}
+		}
+	}
+
+	for _, bad := range []string{`height>=`, `(module=consensus`, `descrip~"(unclosed"`, `module consensus`} {
+		if _, err := reader.ParseQuery(bad, nodes); err == nil {
+			t.Errorf("expected an error parsing %s", bad)
+		}
+	}
+
+	//the range is inclusive to the precision it's given in
+	query, _ := reader.ParseQuery(`module=consensus`, nodes)
+	matches, err := reader.QueryEntries(entries, query, "08-14", "00:00:01", "08-14", "00:00:02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Time != "00:00:01.000" {
+		t.Errorf("expected the entries at 1s and 2s, received %v", matches)
+	}
+
+	//an open start takes in everything up to the end
+	matches, err = reader.QueryEntries(entries, query, "", "", "08-14", "00:00:01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[1].Time != "00:00:01.000" {
+		t.Errorf("expected the entries at 0s and 1s, received %v", matches)
+	}
+
+	//a range running into the new year, in a log that does too
+	newYear := []reader.LogEntry{
+		reader.LogEntry{"I", "12-31", "23:59:58.000", "Receive", "consensus", map[string]string{}},
+		reader.LogEntry{"I", "12-31", "23:59:59.000", "Receive", "consensus", map[string]string{}},
+		reader.LogEntry{"I", "01-01", "00:00:00.000", "Receive", "consensus", map[string]string{}},
+		reader.LogEntry{"I", "01-01", "00:00:01.000", "Receive", "consensus", map[string]string{}},
+	}
+
+	matches, err = reader.QueryEntries(newYear, query, "12-31", "23:59:59", "01-01", "00:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].Time != "23:59:59.000" || matches[1].Time != "00:00:00.000" {
+		t.Errorf("expected the entries either side of New Year, received %v", matches)
+	}
+
+	//times after New Year fall in the year the log runs into
+	matches, err = reader.QueryEntries(newYear, query, "01-01", "00:00:01", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Time != "00:00:01.000" {
+		t.Errorf("expected the last entry, received %v", matches)
+	}
+}
@@ -3,9 +3,9 @@ package reader
 import (
 	"fmt"
 	"log"
-	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 //LogEntry defines params for eventual json
@@ -93,52 +93,89 @@ func GetStatus(entries []LogEntry, nodes []Node, date string, time string) (Stat
 		}
 		//
 
-		if strings.Contains(entry.Descrip, "enter") && !strings.Contains(entry.Descrip, "Invalid") && !strings.Contains(entry.Descrip, "Wait") {
-			status, err = newStep(status, entry, nodes)
-			if err != nil {
-				return status, err
-			}
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return status, err
 		}
+	}
+	return status, err
+}
 
-		if entry.Descrip == "Received complete proposal block" {
-			status, err = checkProp(status, entry)
-			if err != nil {
-				return status, err
-			}
+//*********************************************************************following functions belong to GetStatus***********************************************
+
+//updateStatus applies a single log entry to status; bpArr tracks block parts already seen this round
+func updateStatus(status Status, entry LogEntry, nodes []Node, myIP string, bpArr []string) (Status, []string, error) {
+	var err error
+
+	if isStep(entry) {
+		status, err = newStep(status, entry, nodes)
+		if err != nil {
+			return status, bpArr, err
 		}
+	}
 
-		if entry.Descrip == "Receive" && strings.Contains(entry.Other["msg"], "BlockPart") {
-			status, bpArr, err = checkBlock(status, entry, bpArr)
-			if err != nil {
-				return status, err
-			}
+	if entry.Descrip == "Received complete proposal block" {
+		status, err = checkProp(status, entry)
+		if err != nil {
+			return status, bpArr, err
 		}
+	}
 
-		if entry.Descrip == "Receive" && strings.Contains(entry.Other["msg"], "Vote Vote") {
-			status, err = checkVotes(status, entry, nodes)
-			if err != nil {
-				return status, err
-			}
+	if entry.Descrip == "Receive" && strings.Contains(entry.Other["msg"], "BlockPart") {
+		status, bpArr, err = checkBlock(status, entry, bpArr)
+		if err != nil {
+			return status, bpArr, err
 		}
+	}
 
-		if entry.Descrip == "Signed and pushed vote" {
-			status, err = checkMyVote(status, entry, myIP, nodes)
-			if err != nil {
-				return status, err
-			}
+	if entry.Descrip == "Receive" && strings.Contains(entry.Other["msg"], "Vote Vote") {
+		status, err = checkVotes(status, entry, nodes)
+		if err != nil {
+			return status, bpArr, err
 		}
+	}
 
-		if strings.Contains(entry.Descrip, "Picked") && strings.Contains(entry.Descrip, "Pre") {
-			status, err = checkExpected(status, entry, nodes, myIP)
-			if err != nil {
-				return status, err
-			}
+	if entry.Descrip == "Signed and pushed vote" {
+		status, err = checkMyVote(status, entry, myIP, nodes)
+		if err != nil {
+			return status, bpArr, err
 		}
 	}
-	return status, err
+
+	if strings.Contains(entry.Descrip, "Picked") && strings.Contains(entry.Descrip, "Pre") {
+		status, err = checkExpected(status, entry, nodes, myIP)
+		if err != nil {
+			return status, bpArr, err
+		}
+	}
+	return status, bpArr, err
 }
 
-//*********************************************************************following functions belong to GetStatus***********************************************
+//heightRound parses height and round from a step's descrip (eg. "enterNewRound(8/0). Current: 8/0/RoundStepNewHeight")
+func heightRound(descrip string) (int, int, error) {
+	hrs := strings.Split(descrip, " ")[0]
+	hrParse := strings.Split(hrs, "(")
+	if len(hrParse) < 2 || !strings.Contains(hrParse[1], "/") {
+		return 0, 0, fmt.Errorf("no height/round in %q", descrip)
+	}
+
+	height, err := strconv.Atoi(strings.Split(hrParse[1], "/")[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	round, err := strconv.Atoi(strings.Split(strings.Split(hrParse[1], "/")[1], ")")[0])
+	return height, round, err
+}
+
+//isStep reports whether entry marks a change of consensus step; only the function name is checked for "Wait",
+//since the current step often contains it too. Checking the whole descrip, as GetStatus once did, skipped every step
+//entered from a wait (eg. "enterPrecommit(1/0). Current: 1/0/RoundStepPrevoteWait"), so state stayed in the last step
+//and round changes after a precommit timeout were missed
+func isStep(entry LogEntry) bool {
+	step := strings.Split(entry.Descrip, "(")[0]
+	return strings.HasPrefix(step, "enter") && !strings.HasSuffix(step, "Wait") && !strings.Contains(entry.Descrip, "Invalid")
+}
 
 //check for new round; set HRS, and reset votes if new round
 func newStep(status Status, entry LogEntry, nodes []Node) (Status, error) {
@@ -147,17 +184,7 @@ func newStep(status Status, entry LogEntry, nodes []Node) (Status, error) {
 	descrip := entry.Descrip
 
 	if strings.Contains(descrip, "enterNewRound") {
-		hrs := strings.Split(descrip, " ")[0]
-		heightRound := strings.Split(hrs, "(")[1]
-		height := strings.Split(heightRound, "/")[0]
-		round := strings.Split(heightRound, "/")[1]
-		round = strings.Split(round, ")")[0]
-
-		status.Height, err = strconv.Atoi(height)
-		if err != nil {
-			return status, err
-		}
-		status.Round, err = strconv.Atoi(round)
+		status.Height, status.Round, err = heightRound(descrip)
 		if err != nil {
 			return status, err
 		}
@@ -382,181 +409,179 @@ func checkExpected(status Status, entry LogEntry, nodes []Node, myIP string) (St
 
 //*****************************************************************************end of GetStatus functions*************************************************
 
-func GetMessages(entries []LogEntry, nodes []Node, dur int, stD string, stT string, enD string, enT string, commits bool) ([][]string, error) {
-	var trackers = make(map[string]int)
-	var timeParams = make(map[string]int)
-	var timeStamp string
+func GetMessages(entries []LogEntry, nodes []Node, dur time.Duration, stD string, stT string, enD string, enT string, commits bool) ([][]string, error) {
+	_, msgArr, err := messageRows(entries, nodes, dur, stD, stT, enD, enT, commits, true)
+	return msgArr, err
+}
 
-	var parse bool
-	var watch bool
-	var err error
+//MessageRows returns the same rows as GetMessages, along with the start of each interval, without printing anything
+func MessageRows(entries []LogEntry, nodes []Node, dur time.Duration, stD string, stT string, enD string, enT string) ([]string, [][]string, error) {
+	return messageRows(entries, nodes, dur, stD, stT, enD, enT, false, false)
+}
 
-	var msgArr [][]string
+//PeerRows turns rows from MessageRows on their side: a row per node, in the order nodes are given, across every interval
+func PeerRows(msgArr [][]string, nodes []Node) ([][]string, error) {
+	var rows [][]string
 
-	first := true
+	for _, node := range nodes {
+		index, err := strconv.Atoi(node.Index)
+		if err != nil {
+			return rows, err
+		}
 
-	lenNodes := len(nodes)
+		var row []string
+		for _, msgs := range msgArr {
+			if index < 0 || index >= len(msgs) {
+				return rows, fmt.Errorf("node index %d is out of range", index)
+			}
+			row = append(row, msgs[index])
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func messageRows(entries []LogEntry, nodes []Node, dur time.Duration, stD string, stT string, enD string, enT string, commits bool, print bool) ([]string, [][]string, error) {
+	var timeStamps []string
+	var msgArr [][]string
 
 	myIp := findMyIP(entries)
 
-	var msgs []string
+	msgs := emptyRow(len(nodes))
 
-	//set msgs
-	for i := 0; i < len(nodes); i++ {
-		msgs = append(msgs, "_")
+	read := func(entry LogEntry) error {
+		var err error
+		msgs, err = getMsg(msgs, entry, nodes, myIp, commits)
+		return err
 	}
 
-	for _, entry := range entries {
-
-		//check if we should start parsing
-		if entry.Date == stD && strings.Contains(entry.Time, stT) && parse == false {
-			parse = true
+	//prints msgs to console (if asked), adds them to the array, and resets them
+	flush := func(timeStamp string) {
+		if print {
+			fmt.Println(timeStamp, msgs)
 		}
+		timeStamps = append(timeStamps, timeStamp)
+		msgArr = append(msgArr, msgs)
+		msgs = emptyRow(len(nodes))
+	}
 
-		//check if we've reached end time (continue parsing until this time has passed)
-		if entry.Date == enD && strings.Contains(entry.Time, enT) {
-			watch = true
-		}
+	err := walkIntervals(entries, dur, stD, stT, enD, enT, read, flush)
 
-		if parse == true {
-			//parse the entry for time parameters
-			timeParams, err = parseEntryTime(timeParams, entry)
-			//return if parse time has elapsed
-			if watch == true && !strings.Contains(entry.Time, enT) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				return msgArr, err
-			}
+	return timeStamps, msgArr, err
+}
 
-			//set up our trackers if first log entry read
-			if first == true {
-				timeStamp = entry.Time
-				trackers = updateParams(trackers, timeParams)
-				first = false
-			}
+//MaxIntervals caps how many intervals a range may be split into, so a tiny interval over a long range (eg. 1ms over a
+//week) is refused rather than producing billions of rows
+const MaxIntervals = 100000
+
+//walkIntervals passes each entry from the start time through the end time to read, and calls flush with the start of
+//each interval of length dur once it's over. Intervals are aligned to wall-clock multiples of dur (eg. a 10m interval
+//starts on 04:30, 04:40...) and are flushed even when empty, so quiet stretches show up as empty rows. Logs carry no
+//year, so one is carried forward whenever the month goes backwards (eg. from 12-31 to 01-01)
+func walkIntervals(entries []LogEntry, dur time.Duration, stD string, stT string, enD string, enT string, read func(LogEntry) error, flush func(string)) error {
+	if dur <= 0 {
+		return fmt.Errorf("interval must be positive, received %s", dur)
+	}
 
-			//msgs will reset once printed
-			printed := false
+	start, _, err := ParseBound(stD, stT)
+	if err != nil {
+		return err
+	}
 
-			//this if/else statement determines when to return a statement, accounting for the turnover of minutes, hours, days, months, years
-			if normalElapse(timeParams["time"], trackers, dur) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				printed = true
+	//the end time is inclusive, to whatever precision it was given in
+	end, precision, err := ParseBound(enD, enT)
+	if err != nil {
+		return err
+	}
+	end = end.Add(precision)
 
-			} else if nextMinute(timeParams["minute"], trackers, timeParams["time"], dur) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				printed = true
+	//a range ending in an earlier month than it starts crosses New Year
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
 
-			} else if nextHour(timeParams["hour"], trackers, timeParams["time"], dur) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				printed = true
+	//a log that begins in a later month than the range starts began the year before it
+	if len(entries) > 0 {
+		first, err := EntryTime(entries[0])
+		if err != nil {
+			return err
+		}
+		if first.Month() > start.Month() {
+			start, end = start.AddDate(1, 0, 0), end.AddDate(1, 0, 0)
+		}
+	}
 
-			} else if nextDay(timeParams["day"], trackers, timeParams["time"], dur) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				printed = true
+	interval := start.Truncate(dur)
 
-			} else if nextMonth(timeParams["month"], trackers, timeParams["time"], dur) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				printed = true
+	if end.Sub(interval)/dur > MaxIntervals {
+		return fmt.Errorf("%s intervals from %s %s to %s %s would make more than %d rows; use a longer interval", dur, stD, stT, enD, enT, MaxIntervals)
+	}
 
-			}
+	var rollover YearRollover
 
-			//reset msgs if logged
-			if printed {
-				msgs = []string{}
+	for _, entry := range entries {
+		entryT, err := rollover.EntryTime(entry)
+		if err != nil {
+			return err
+		}
 
-				for i := 0; i < lenNodes; i++ {
-					msgs = append(msgs, "_")
-				}
-			}
+		if entryT.Before(start) {
+			continue
+		}
 
-			//read entry and update msgs
-			msgs, err = getMsg(msgs, entry, nodes, myIp, commits)
-			if err != nil {
-				return msgArr, err
-			}
+		if !entryT.Before(end) {
+			break
+		}
 
-			//return if last entry
-			if logEnds(entry, entries) {
-				trackers, msgs, msgArr = printAndReset(timeStamp, trackers, msgs, entry, timeParams, lenNodes, msgArr)
-				return msgArr, err
-			}
+		//flush every interval that's over, empty or not
+		for !entryT.Before(interval.Add(dur)) {
+			flush(interval.Format(timeLayout))
+			interval = interval.Add(dur)
+		}
 
-			//reset timestamp
-			timeStamp = entry.Time
+		err = read(entry)
+		if err != nil {
+			return err
 		}
 	}
-	return msgArr, err
-}
-
-//*********************************************************************following functions belong to GetMsgs***********************************************
-
-func parseEntryTime(timeParams map[string]int, entry LogEntry) (map[string]int, error) {
-
-	var err error
-
-	tParse := strings.Split(entry.Time, ":")
-	timeParse := strings.Split(tParse[2], ".")
-	mili := timeParse[0] + timeParse[1]
 
-	timeParams["minute"], err = strconv.Atoi(tParse[1])
-	if err != nil {
-		return timeParams, err
-	}
-	timeParams["hour"], err = strconv.Atoi(tParse[0])
-	if err != nil {
-		return timeParams, err
+	//flush what's left through the end time
+	for interval.Before(end) {
+		flush(interval.Format(timeLayout))
+		interval = interval.Add(dur)
 	}
+	return err
+}
 
-	dateParse := strings.Split(entry.Date, "-")
-	timeParams["month"], err = strconv.Atoi(dateParse[0])
-	if err != nil {
-		return timeParams, err
-	}
-	timeParams["day"], err = strconv.Atoi(dateParse[1])
-	if err != nil {
-		return timeParams, err
-	}
+//YearRollover converts a run of entries' times, carrying the year forward whenever the month goes backwards
+type YearRollover struct {
+	month time.Month
+	years int
+}
 
-	timeParams["time"], err = strconv.Atoi(mili)
+//EntryTime converts an entry's time, in the year carried forward so far
+func (rollover *YearRollover) EntryTime(entry LogEntry) (time.Time, error) {
+	entryT, err := EntryTime(entry)
 	if err != nil {
-		return timeParams, err
+		return entryT, err
 	}
 
-	return timeParams, err
-}
-
-//prints msgs to console, updates time parameters, resets msgs array
-func printAndReset(timeStamp string, trackers map[string]int, msgs []string, entry LogEntry, timeParams map[string]int, lenNodes int, msgArr [][]string) (map[string]int, []string, [][]string) {
-	//print to command line
-	fmt.Println(timeStamp, msgs)
-
-	//update trackers
-	trackers = updateParams(trackers, timeParams)
-
-	//add msgs to array
-	msgArr = append(msgArr, msgs)
+	if entryT.Month() < rollover.month {
+		rollover.years++
+	}
+	rollover.month = entryT.Month()
 
-	return trackers, msgs, msgArr
+	return entryT.AddDate(rollover.years, 0, 0), nil
 }
 
-//updates time after checking how much has elapsed
-func updateParams(trackers map[string]int, timeParams map[string]int) map[string]int {
-	trackers["trackTime"] = timeParams["time"]
-	trackers["prevMinute"] = timeParams["minute"]
-	trackers["prevHour"] = timeParams["hour"]
-	trackers["prevDay"] = timeParams["day"]
-	trackers["prevMonth"] = timeParams["month"]
-
-	return trackers
-}
+//*********************************************************************following functions belong to GetMsgs***********************************************
 
 //processing logic for received msgs
 func getMsg(msgs []string, entry LogEntry, nodes []Node, myIp string, commits bool) ([]string, error) {
 	if entry.Descrip == "Receive" {
 
-		nodeParse := strings.Split(entry.Other["src"], "}")[0]
-		ipParse := strings.Split(nodeParse, "{")[2]
-		ipParse = strings.Split(ipParse, ":")[0]
+		ipParse := peerIP(entry)
 
 		for _, node := range nodes {
 
@@ -591,49 +616,94 @@ func getMsg(msgs []string, entry LogEntry, nodes []Node, myIp string, commits bo
 
 }
 
-func logEnds(entry LogEntry, entries []LogEntry) bool {
-	return reflect.DeepEqual(entry, entries[len(entries)-1])
-}
+//emptyRow returns a msgs row with no marks
+func emptyRow(lenNodes int) []string {
+	var msgs []string
 
-func nextMonth(month int, trackers map[string]int, time int, dur int) bool {
-	return (month > trackers["prevMonth"] || month < trackers["prevMonth"]) && rolloverElapse(time, trackers, dur)
+	for i := 0; i < lenNodes; i++ {
+		msgs = append(msgs, "_")
+	}
+	return msgs
 }
 
-func nextDay(day int, trackers map[string]int, time int, dur int) bool {
-	return day > trackers["prevDay"] && rolloverElapse(time, trackers, dur)
-}
+//*****************************************************************************end of GetStatus functions*************************************************
 
-func nextHour(hour int, trackers map[string]int, time int, dur int) bool {
-	return hour > trackers["prevHour"] && rolloverElapse(time, trackers, dur)
+//*******************************************************************following functions are called in multiple contexts**********************************
+
+//findMyIP finds the current node's IP
+func findMyIP(entries []LogEntry) string {
+	for _, entry := range entries {
+		if entry.Descrip == "Starting DefaultListener" {
+			return listenerIP(entry)
+		}
+	}
+	return ""
 }
 
-func nextMinute(minute int, trackers map[string]int, time int, dur int) bool {
-	return minute > trackers["prevMinute"] && rolloverElapse(time, trackers, dur)
+//listenerIP parses the node's own IP out of its "Starting DefaultListener" entry
+func listenerIP(entry LogEntry) string {
+	ipParse := strings.Split(entry.Other["impl"], "@")[1]
+	ip := strings.Replace(ipParse, ")", "", 1)
+	return strings.Split(ip, ":")[0]
 }
 
-func normalElapse(time int, trackers map[string]int, dur int) bool {
-	return time > trackers["trackTime"] && (time-trackers["trackTime"]) >= dur
+//timeLayout is how log entries write date and time
+const timeLayout = "01-02 15:04:05.000"
+
+//EntryTime converts an entry's date and time to a time.Time (logs carry no year, so year 0 is used)
+func EntryTime(entry LogEntry) (time.Time, error) {
+	return time.Parse(timeLayout, entry.Date+" "+entry.Time)
 }
 
-func rolloverElapse(time int, trackers map[string]int, dur int) bool {
-	return ((60000-trackers["trackTime"]+time) >= dur || time > trackers["trackTime"])
+//ParseTime converts a date (01-01) and a time given to any precision (00, 00:00, 00:00:00, 00:00:00.000) to a time.Time
+func ParseTime(date string, clock string) (time.Time, error) {
+	at, _, err := ParseBound(date, clock)
+	return at, err
 }
 
-//*****************************************************************************end of GetStatus functions*************************************************
+//ParseBound converts a date (01-01) and a time given to any precision (00, 00:00, 00:00:00, 00:00:00.000)
+//to a time.Time, also returning the span of time it names (eg. 1s for 00:00:00)
+func ParseBound(date string, clock string) (time.Time, time.Duration, error) {
+	var layout string
+	var precision time.Duration
+
+	switch strings.Count(clock, ":") {
+	case 0:
+		layout, precision = "01-02 15", time.Hour
+	case 1:
+		layout, precision = "01-02 15:04", time.Minute
+	default:
+		layout, precision = "01-02 15:04:05", time.Second
+	}
 
-//*******************************************************************following functions are called in multiple contexts**********************************
+	//fractional seconds parse without being in the layout, but narrow the span
+	if fraction := strings.Split(clock, "."); len(fraction) == 2 {
+		for i := 0; i < len(fraction[1]); i++ {
+			precision = precision / 10
+		}
+	}
 
-//findMyIP finds the current node's IP
-func findMyIP(entries []LogEntry) string {
-	var ip string
+	bound, err := time.Parse(layout, date+" "+clock)
+	return bound, precision, err
+}
 
-	for _, entry := range entries {
-		if entry.Descrip == "Starting DefaultListener" {
-			ipParse := strings.Split(entry.Other["impl"], "@")[1]
-			ip = strings.Replace(ipParse, ")", "", 1)
-			ip = strings.Split(ip, ":")[0]
-			break
-		}
+//copyStatus returns a copy of status that shares no arrays with the original
+func copyStatus(status Status) Status {
+	status.BlockParts = append([]string(nil), status.BlockParts...)
+	status.PreVotes = append([]string(nil), status.PreVotes...)
+	status.PreCommits = append([]string(nil), status.PreCommits...)
+	status.XPreVotes = append([]string(nil), status.XPreVotes...)
+	status.XPreCommits = append([]string(nil), status.XPreCommits...)
+
+	return status
+}
+
+//peerIP pulls the peer's IP out of a "Receive" entry's src (eg. "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}")
+func peerIP(entry LogEntry) string {
+	nodeParse := strings.Split(entry.Other["src"], "}")[0]
+	ipParse := strings.Split(nodeParse, "{")
+	if len(ipParse) < 3 {
+		return ""
 	}
-	return ip
+	return strings.Split(ipParse[2], ":")[0]
 }
@@ -3,6 +3,7 @@ package reader_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/joshkestenberg/t-logs/reader"
 )
@@ -185,6 +186,33 @@ func TestGetStatus(t *testing.T) {
 				1, 0, "Commit", "No", []string{}, []string{"Y", "X", "_", "_", "N"}, []string{"X", "_", "_", "_", "N"}, []string{"_", "_", "_", "X", "N"}, []string{"_", "_", "_", "X", "N"},
 			},
 		},
+		{
+			//steps entered from a wait step count (their current step names the wait), while the waits themselves don't
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "0", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "1", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrevoteWait(1/0). Current: 1/0/RoundStepPrevote", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrecommit(1/0). Current: 1/0/RoundStepPrevoteWait", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrecommitWait(1/0). Current: 1/0/RoundStepPrecommit", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			reader.Status{
+				1, 1, "NewRound", "No", []string{}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"},
+			},
+		},
+		{
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "0", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+				reader.LogEntry{"", "08-14", "1", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrevoteWait(1/0). Current: 1/0/RoundStepPrevote", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "1", "enterPrecommit(1/0). Current: 1/0/RoundStepPrevoteWait", "consensus", map[string]string{}},
+			},
+			reader.Status{
+				1, 0, "Precommit", "No", []string{}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"},
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		status, _ := reader.GetStatus(testCase.entries, nodes, "08-14", "1")
@@ -198,9 +226,17 @@ func TestGetMessages(t *testing.T) {
 
 	nodes := NODES
 
+	//08-14, 08-15, 28 quiet days, 09-13, a quiet day, then 09-15
+	monthRows := [][]string{{"_", "X", "O", "X", "X"}, {"_", "_", "_", "_", "X"}}
+	for i := 0; i < 28; i++ {
+		monthRows = append(monthRows, []string{"_", "_", "_", "_", "_"})
+	}
+	monthRows = append(monthRows, []string{"_", "_", "_", "_", "X"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "X", "_"})
+
 	testCases := []struct {
 		entries            []reader.LogEntry
 		msgArr             [][]string
+		dur                time.Duration
 		stD, enD, stT, enT string
 	}{
 		{
@@ -221,12 +257,14 @@ func TestGetMessages(t *testing.T) {
 			msgArr: [][]string{
 				{"_", "_", "_", "_", "_"}, {"X", "_", "_", "_", "_"}, {"_", "X", "_", "_", "_"}, {"_", "_", "O", "_", "_"}, {"_", "_", "_", "X", "_"}, {"_", "_", "_", "_", "X"},
 			},
+			dur: time.Millisecond,
 			stD: "08-14",
 			enD: "08-14",
 			stT: "00:00:00.000",
 			enT: "00:00:00.005",
 		},
 		{
+			//intervals with nothing received are still returned, through the end time
 			entries: []reader.LogEntry{
 				reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
 				reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "",
@@ -241,15 +279,17 @@ func TestGetMessages(t *testing.T) {
 					map[string]string{"src": "Peer{MConn{172.31.32.72:46656} D7ECACFDC10F in}"}},
 			},
 			msgArr: [][]string{
-				{"_", "_", "_", "_", "_"}, {"X", "X", "O", "X", "X"},
+				{"_", "_", "_", "_", "_"}, {"X", "X", "O", "X", "X"}, {"_", "_", "_", "_", "_"}, {"_", "_", "_", "_", "_"}, {"_", "_", "_", "_", "_"}, {"_", "_", "_", "_", "_"},
 			},
+			dur: time.Millisecond,
 			stD: "08-14",
 			enD: "08-14",
 			stT: "00:00:00.000",
 			enT: "00:00:00.005",
 		},
 		{
-			//ensure parser starts at correct time; all rollovers work; parser stops before log end if needed
+			//ensure parser starts at correct time; all rollovers work; parser stops before log end if needed (a day per row, as
+			//a row per ms over a month would be billions of rows)
 			entries: []reader.LogEntry{
 				reader.LogEntry{"", "08-14", "00:00:00.996", "Receive", "",
 					map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
@@ -281,22 +321,88 @@ func TestGetMessages(t *testing.T) {
 				reader.LogEntry{"", "09-16", "00:00:00.998", "Receive", "",
 					map[string]string{"src": "Peer{MConn{172.31.32.72:46656} 1BF7CA4820CD out}"}},
 			},
+			msgArr: monthRows,
+			dur:    24 * time.Hour,
+			stD:    "08-14",
+			enD:    "09-15",
+			stT:    "00:00:00.997",
+			enT:    "01:00:01.005",
+		},
+		{
+			//intervals line up with the clock, and a quiet stretch shows up as empty rows
+			entries: []reader.LogEntry{
+				reader.LogEntry{"", "08-14", "04:33:39.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
+				reader.LogEntry{"", "08-14", "04:33:39.426", "Receive", "",
+					map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
+				reader.LogEntry{"", "08-14", "04:41:00.000", "Receive", "",
+					map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}"}},
+				reader.LogEntry{"", "08-14", "05:02:00.000", "Receive", "",
+					map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
+			},
 			msgArr: [][]string{
-				{"_", "X", "_", "_", "_"}, {"_", "_", "O", "X", "_"}, {"_", "_", "_", "_", "X"}, {"_", "_", "_", "X", "X"}, {"_", "_", "_", "_", "X"}, {"_", "_", "_", "_", "X"}, {"_", "_", "_", "_", "X"}, {"_", "_", "_", "_", "X"}, {"_", "_", "_", "X", "_"},
+				{"X", "_", "_", "_", "_"}, {"_", "X", "_", "_", "_"}, {"_", "_", "_", "_", "_"}, {"X", "_", "_", "_", "_"},
 			},
+			dur: 10 * time.Minute,
 			stD: "08-14",
-			enD: "09-15",
-			stT: "00:00:00.997",
-			enT: "01:00:01.005",
+			enD: "08-14",
+			stT: "04:33:39",
+			enT: "05:02",
+		},
+		{
+			//the year rolls over with the month
+			entries: []reader.LogEntry{
+				reader.LogEntry{"", "12-31", "23:59:58.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
+				reader.LogEntry{"", "12-31", "23:59:59.500", "Receive", "",
+					map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}"}},
+				reader.LogEntry{"", "01-01", "00:00:00.500", "Receive", "",
+					map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}"}},
+			},
+			msgArr: [][]string{
+				{"X", "_", "_", "_", "_"}, {"_", "X", "_", "_", "_"},
+			},
+			dur: time.Second,
+			stD: "12-31",
+			enD: "01-01",
+			stT: "23:59:59",
+			enT: "00:00:00",
 		},
 	}
 
 	for _, testCase := range testCases {
 
-		msgArr, _ := reader.GetMessages(testCase.entries, nodes, 1, testCase.stD, testCase.stT, testCase.enD, testCase.enT, true)
+		msgArr, _ := reader.GetMessages(testCase.entries, nodes, testCase.dur, testCase.stD, testCase.stT, testCase.enD, testCase.enT, true)
 		if !reflect.DeepEqual(testCase.msgArr, msgArr) {
 			t.Errorf("expected %s, received %s", testCase.msgArr, msgArr)
 		}
 	}
 
+	//too many intervals are refused rather than walked
+	_, err := reader.GetMessages(testCases[2].entries, nodes, time.Millisecond, "08-14", "00:00:00.997", "09-15", "01:00:01.005", true)
+	if err == nil {
+		t.Error("expected an error for 1ms intervals over a month")
+	}
+
+}
+
+func TestPeerRows(t *testing.T) {
+	//nodes.json needn't list nodes by index; each row still belongs to the node it's labelled with
+	nodes := []reader.Node{NODES[2], NODES[0], NODES[4], NODES[1], NODES[3]}
+
+	msgArr := [][]string{{"X", "_", "O", "_", "_"}, {"_", "X", "_", "_", "X"}}
+
+	expRows := [][]string{{"O", "_"}, {"X", "_"}, {"_", "X"}, {"_", "X"}, {"_", "_"}}
+
+	rows, err := reader.PeerRows(msgArr, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expRows, rows) {
+		t.Errorf("expected %s, received %s", expRows, rows)
+	}
+
+	_, err = reader.PeerRows(msgArr, append(nodes, reader.Node{"", "172.31.32.73", "", "5"}))
+	if err == nil {
+		t.Errorf("expected an error for an index past the end of a row")
+	}
 }
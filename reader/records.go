@@ -0,0 +1,213 @@
+package reader
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Records holds what a log's entries say about votes, proposals, block parts, steps and peers, a slice per kind, for
+//export to tables. Line is the index of the entry the record came from. Skipped counts votes that couldn't be read
+type Records struct {
+	Votes      []VoteRecord
+	Proposals  []ProposalRecord
+	BlockParts []BlockPartRecord
+	Steps      []StepRecord
+	Peers      []PeerRecord
+	Skipped    int
+}
+
+//VoteRecord is a vote received from a peer (From) or sent by the node (Sent)
+type VoteRecord struct {
+	Line      int
+	Date      string
+	Time      string
+	Height    int
+	Round     int
+	Type      string
+	Validator string
+	Nil       bool
+	Sent      bool
+	From      string
+}
+
+//ProposalRecord is one of the proposal events below; Proposer is only known for turn events
+type ProposalRecord struct {
+	Line     int
+	Date     string
+	Time     string
+	Height   int
+	Round    int
+	Event    string
+	Proposer string
+}
+
+//proposal events
+const (
+	ProposalTurn     = "turn"
+	ProposalReceived = "received"
+	ProposalComplete = "complete"
+	ProposalInvalid  = "invalid"
+)
+
+//BlockPartRecord is a block part received from a peer
+type BlockPartRecord struct {
+	Line   int
+	Date   string
+	Time   string
+	Height int
+	Round  int
+	Part   int
+	From   string
+}
+
+//StepRecord marks the node entering a step, as newStep finds it
+type StepRecord struct {
+	Line   int
+	Date   string
+	Time   string
+	Height int
+	Round  int
+	Step   string
+}
+
+//PeerRecord is a peer being added or stopped, or failing to dial. Name is empty for peers not in nodes.json
+type PeerRecord struct {
+	Line      int
+	Date      string
+	Time      string
+	Event     string
+	Name      string
+	Ip        string
+	Direction string
+	Err       string
+}
+
+//GetRecords replays entries once, collecting records of every kind
+func GetRecords(entries []LogEntry, nodes []Node) (Records, error) {
+	var err error
+	var records Records
+	var status Status
+	var bpArr []string
+
+	status.Proposal = "No"
+
+	myIP := findMyIP(entries)
+	me := findName(nodes, myIP)
+
+	for line, entry := range entries {
+		prev := status
+
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return records, err
+		}
+
+		if status.Height != prev.Height || status.Round != prev.Round || status.Step != prev.Step {
+			records.Steps = append(records.Steps, StepRecord{line, entry.Date, entry.Time, status.Height, status.Round, status.Step})
+		}
+
+		msg := entry.Other["msg"]
+		from := findName(nodes, peerIP(entry))
+
+		switch {
+		case entry.Descrip == "Receive" && strings.Contains(msg, "Vote Vote"), entry.Descrip == "Signed and pushed vote":
+			sent := entry.Descrip == "Signed and pushed vote"
+			if sent {
+				msg, from = entry.Other["vote"], ""
+			}
+
+			//one garbled vote shouldn't keep the rest of the log out of the tables
+			vote, err := parseVote(msg)
+			if err != nil {
+				records.Skipped++
+				continue
+			}
+
+			records.Votes = append(records.Votes, VoteRecord{line, entry.Date, entry.Time, vote.Height, vote.Round, vote.Type, nodeName(nodes, vote.Index), vote.Nil, sent, from})
+
+		case entry.Descrip == "Receive" && strings.Contains(msg, "BlockPart"):
+			height, round, part := parseBlockPart(msg)
+			records.BlockParts = append(records.BlockParts, BlockPartRecord{line, entry.Date, entry.Time, height, round, part, from})
+
+		case strings.Contains(entry.Descrip, "Our turn to propose"):
+			records.Proposals = append(records.Proposals, ProposalRecord{line, entry.Date, entry.Time, status.Height, status.Round, ProposalTurn, me})
+
+		case strings.Contains(entry.Descrip, "Not our turn to propose"):
+			records.Proposals = append(records.Proposals, ProposalRecord{line, entry.Date, entry.Time, status.Height, status.Round, ProposalTurn, findProposer(nodes, entry.Other["proposer"])})
+
+		case entry.Descrip == "Received proposal", entry.Descrip == "Receive" && strings.Contains(msg, "Proposal Proposal"):
+			records.Proposals = append(records.Proposals, ProposalRecord{line, entry.Date, entry.Time, status.Height, status.Round, ProposalReceived, ""})
+
+		case entry.Descrip == "Received complete proposal block":
+			height, _ := strconv.Atoi(entry.Other["height"])
+			records.Proposals = append(records.Proposals, ProposalRecord{line, entry.Date, entry.Time, height, status.Round, ProposalComplete, ""})
+
+		case isInvalidProposal(entry):
+			records.Proposals = append(records.Proposals, ProposalRecord{line, entry.Date, entry.Time, status.Height, status.Round, ProposalInvalid, ""})
+
+		case isPeerEvent(entry):
+			records.Peers = append(records.Peers, peerRecord(line, entry, nodes))
+		}
+	}
+	return records, err
+}
+
+//*********************************************************************following functions belong to GetRecords***********************************************
+
+//parseBlockPart reads height, round and part index from a block part msg, eg. "[BlockPart H:1 R:0 P:Part{#0 ..."
+func parseBlockPart(msg string) (int, int, int) {
+	var height, round, part int
+
+	for _, field := range strings.Fields(msg) {
+		if strings.HasPrefix(field, "H:") {
+			height = leadingInt(strings.TrimPrefix(field, "H:"))
+		} else if strings.HasPrefix(field, "R:") {
+			round = leadingInt(strings.TrimPrefix(field, "R:"))
+		} else if strings.HasPrefix(field, "P:Part{#") {
+			part = leadingInt(strings.TrimPrefix(field, "P:Part{#"))
+		}
+	}
+	return height, round, part
+}
+
+//leadingInt reads the digits a string starts with, as msgs run fields into whatever follows them (eg. "0\n  Bytes")
+func leadingInt(str string) int {
+	end := strings.IndexFunc(str, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(str)
+	}
+
+	num, _ := strconv.Atoi(str[:end])
+	return num
+}
+
+//isPeerEvent checks for the p2p entries that mark a connection coming or going
+func isPeerEvent(entry LogEntry) bool {
+	switch entry.Descrip {
+	case "Added peer", "Stopping peer for error", "Stopping peer", "Stopping Peer", "Error dialing peer", "Dialing peer",
+		"Reconnecting to peer", "Failed to reconnect to peer. Giving up":
+		return true
+	}
+	return false
+}
+
+//peerRecord reads a peer event. The peer is logged as peer, as address when dialing, or as impl when the peer service stops
+func peerRecord(line int, entry LogEntry, nodes []Node) PeerRecord {
+	ip, direction := parsePeer(entry.Other["peer"] + entry.Other["address"] + entry.Other["impl"])
+	return PeerRecord{line, entry.Date, entry.Time, entry.Descrip, findName(nodes, ip), ip, direction, entry.Other["err"]}
+}
+
+//parsePeer reads the IP and direction (in or out) of a peer as logged, eg. "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}",
+//or the IP of a bare address, eg. "172.31.39.83:46656"
+func parsePeer(peer string) (string, string) {
+	var direction string
+
+	if strings.HasSuffix(peer, " in}") {
+		direction = "in"
+	} else if strings.HasSuffix(peer, " out}") {
+		direction = "out"
+	}
+
+	peer = strings.TrimPrefix(peer, "Peer{MConn{")
+	return strings.Split(peer, ":")[0], direction
+}
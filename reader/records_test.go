@@ -0,0 +1,57 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetRecords(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.002", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "enterPropose: Not our turn to propose", "consensus", map[string]string{"proposer": "3D3074F7A7D01071FF28986CAF619AE16E6A6E25"}},
+		reader.LogEntry{"", "08-14", "00:00:00.004", "Receive", "consensus", map[string]string{"msg": `[BlockPart H:1 R:0 P:Part{#2\n  Bytes: 010101066A65...}]`, "src": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.005", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+		reader.LogEntry{"", "08-14", "00:00:00.006", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.007", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.007", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{garbled}]", "src": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.008", "Stopping peer for error", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}", "err": "EOF"}},
+	}
+
+	records, err := reader.GetRecords(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := reader.Records{
+		Votes: []reader.VoteRecord{
+			reader.VoteRecord{7, "08-14", "00:00:00.007", 1, 0, "Prevote", nodes[1].Name, false, false, nodes[1].Name},
+		},
+		Proposals: []reader.ProposalRecord{
+			reader.ProposalRecord{3, "08-14", "00:00:00.003", 1, 0, reader.ProposalTurn, nodes[1].Name},
+			reader.ProposalRecord{5, "08-14", "00:00:00.005", 1, 0, reader.ProposalComplete, ""},
+		},
+		BlockParts: []reader.BlockPartRecord{
+			reader.BlockPartRecord{4, "08-14", "00:00:00.004", 1, 0, 2, nodes[1].Name},
+		},
+		Steps: []reader.StepRecord{
+			reader.StepRecord{2, "08-14", "00:00:00.002", 1, 0, "NewRound"},
+			reader.StepRecord{3, "08-14", "00:00:00.003", 1, 0, "Propose"},
+			reader.StepRecord{6, "08-14", "00:00:00.006", 1, 0, "Prevote"},
+		},
+		Peers: []reader.PeerRecord{
+			reader.PeerRecord{1, "08-14", "00:00:00.001", "Added peer", nodes[1].Name, "172.31.39.83", "out", ""},
+			reader.PeerRecord{9, "08-14", "00:00:00.008", "Stopping peer for error", nodes[1].Name, "172.31.39.83", "out", "EOF"},
+		},
+		Skipped: 1,
+	}
+
+	if !reflect.DeepEqual(exp, records) {
+		t.Errorf("expected %v, received %v", exp, records)
+	}
+}
@@ -0,0 +1,162 @@
+package reader
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+//a Status is saved every replayInterval entries, so the Status anywhere in the log can be rebuilt without replaying from the start
+const replayInterval = 1000
+
+//Replay rebuilds the Status at any point in a log, stepping forward or back
+type Replay struct {
+	Entries     []LogEntry
+	nodes       []Node
+	myIP        string
+	checkpoints []checkpoint
+	times       []time.Time
+}
+
+type checkpoint struct {
+	status Status
+	bpArr  []string
+}
+
+//NewReplay replays entries once, saving checkpoints along the way
+func NewReplay(entries []LogEntry, nodes []Node) (*Replay, error) {
+	var err error
+	var status Status
+	var bpArr []string
+	var rollover YearRollover
+
+	replay := &Replay{Entries: entries, nodes: nodes, myIP: findMyIP(entries)}
+
+	status.Proposal = "No"
+
+	for i, entry := range entries {
+		if i%replayInterval == 0 {
+			replay.checkpoints = append(replay.checkpoints, checkpoint{copyStatus(status), append([]string(nil), bpArr...)})
+		}
+
+		status, bpArr, err = updateStatus(status, entry, nodes, replay.myIP, bpArr)
+		if err != nil {
+			return replay, err
+		}
+
+		//times are carried across New Year once here, so they can be searched in order
+		entryT, err := rollover.EntryTime(entry)
+		if err != nil {
+			return replay, err
+		}
+		replay.times = append(replay.times, entryT)
+	}
+	return replay, err
+}
+
+//StatusAt returns the Status once the entry at index has been read
+func (replay *Replay) StatusAt(index int) (Status, error) {
+	var err error
+
+	if len(replay.checkpoints) == 0 {
+		return Status{Proposal: "No"}, err
+	}
+
+	start := index / replayInterval
+	status := copyStatus(replay.checkpoints[start].status)
+	bpArr := append([]string(nil), replay.checkpoints[start].bpArr...)
+
+	for i := start * replayInterval; i <= index && i < len(replay.Entries); i++ {
+		status, bpArr, err = updateStatus(status, replay.Entries[i], replay.nodes, replay.myIP, bpArr)
+		if err != nil {
+			return status, err
+		}
+	}
+	return status, err
+}
+
+//FindHeight returns the index of the entry at which the given height begins, or -1 if it never does
+func (replay *Replay) FindHeight(height int) int {
+	for i, entry := range replay.Entries {
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+			entryHeight, _, err := heightRound(entry.Descrip)
+			if err == nil && entryHeight == height {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+//FindTime returns the index of the last entry at the given date and time (to whatever precision the time is given in,
+//as with GetStatus), or of the last entry before it if there's none at that time; -1 if the log starts later
+func (replay *Replay) FindTime(date string, clock string) (int, error) {
+	bound, precision, err := ParseBound(date, clock)
+	if err != nil {
+		return -1, err
+	}
+	end := bound.Add(precision)
+
+	//a log that starts in a later month than the time given must have run into the next year
+	if len(replay.times) > 0 && replay.times[0].Month() > bound.Month() {
+		end = end.AddDate(1, 0, 0)
+	}
+
+	//entries are in time order, so search for the first entry past the end of the given time
+	index := sort.Search(len(replay.times), func(i int) bool {
+		return !replay.times[i].Before(end)
+	})
+
+	return index - 1, err
+}
+
+//EndOfHeight returns the index of the last entry before the height after the given one begins (or the last entry in the
+//log if it never does), or -1 if the given height never begins
+func (replay *Replay) EndOfHeight(height int) int {
+	if replay.FindHeight(height) < 0 {
+		return -1
+	}
+
+	next := replay.FindHeight(height + 1)
+	if next < 0 {
+		return len(replay.Entries) - 1
+	}
+	return next - 1
+}
+
+//NextStep returns the index of the next (dir = 1) or previous (dir = -1) change of step from index, or index if there's none
+func (replay *Replay) NextStep(index int, dir int) int {
+	for i := index + dir; i >= 0 && i < len(replay.Entries); i += dir {
+		if isStep(replay.Entries[i]) {
+			return i
+		}
+	}
+	return index
+}
+
+//Search returns the index of the next entry after from whose descrip, module, or other values contain query, wrapping
+//around to the start of the log, or -1 if no entry does
+func (replay *Replay) Search(query string, from int) int {
+	for n := 1; n <= len(replay.Entries); n++ {
+		i := (from + n) % len(replay.Entries)
+		if entryContains(replay.Entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
+
+//*********************************************************************following functions belong to Replay***********************************************
+
+func entryContains(entry LogEntry, query string) bool {
+	if strings.Contains(entry.Descrip, query) || strings.Contains(entry.Module, query) {
+		return true
+	}
+
+	for key, value := range entry.Other {
+		if strings.Contains(key+"="+value, query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestReplay(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.002", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.004", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+	}
+
+	replay, err := reader.NewReplay(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//replaying to any index should match GetStatus at that entry's time
+	for i, entry := range entries {
+		status, err := replay.StatusAt(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp, _ := reader.GetStatus(entries, nodes, entry.Date, entry.Time)
+		if !reflect.DeepEqual(exp, status) {
+			t.Errorf("at %d expected %v, received %v", i, exp, status)
+		}
+	}
+
+	if index := replay.FindHeight(2); index != 4 {
+		t.Errorf("expected height 2 at 4, received %d", index)
+	}
+
+	if index := replay.NextStep(2, 1); index != 4 {
+		t.Errorf("expected next step at 4, received %d", index)
+	}
+
+	if index := replay.NextStep(2, -1); index != 1 {
+		t.Errorf("expected previous step at 1, received %d", index)
+	}
+
+	if index, _ := replay.FindTime("08-14", "00:00:00.003"); index != 3 {
+		t.Errorf("expected 00:00:00.003 at 3, received %d", index)
+	}
+
+	//a time given to the second takes in every entry in that second
+	if index, _ := replay.FindTime("08-14", "00:00:00"); index != 4 {
+		t.Errorf("expected 00:00:00 to end at 4, received %d", index)
+	}
+
+	if index, _ := replay.FindTime("08-13", "23:59"); index != -1 {
+		t.Errorf("expected nothing before the log starts, received %d", index)
+	}
+
+	if index := replay.EndOfHeight(1); index != 3 {
+		t.Errorf("expected height 1 to end at 3, received %d", index)
+	}
+
+	//search wraps around to the start of the log
+	if index := replay.Search("3D3074F7A7D0", 4); index != 3 {
+		t.Errorf("expected search to find 3, received %d", index)
+	}
+}
+
+func TestReplayFindTimeNewYear(t *testing.T) {
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "12-31", "23:59:59.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "12-31", "23:59:59.500", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "01-01", "00:00:00.001", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "01-01", "00:00:00.002", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+	}
+
+	replay, err := reader.NewReplay(entries, NODES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//times after New Year fall in the year the log runs into
+	if index, _ := replay.FindTime("01-01", "00:00:00.001"); index != 2 {
+		t.Errorf("expected 00:00:00.001 at 2, received %d", index)
+	}
+
+	if index, _ := replay.FindTime("12-31", "23:59:59"); index != 1 {
+		t.Errorf("expected 23:59:59 to end at 1, received %d", index)
+	}
+
+	if index, _ := replay.FindTime("01-02", "00"); index != 3 {
+		t.Errorf("expected the last entry after the log ends, received %d", index)
+	}
+}
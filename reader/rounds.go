@@ -0,0 +1,138 @@
+package reader
+
+import (
+	"strconv"
+	"strings"
+)
+
+//RoundChange explains why a height moved from one round to the next
+type RoundChange struct {
+	Date      string
+	Time      string
+	Height    int
+	FromRound int
+	ToRound   int
+	Cause     string
+	Missing   []string
+	Status    Status
+}
+
+//round change causes, checked in this order
+const (
+	CauseInvalidProposal = "invalid proposal"
+	CauseNoProposal      = "no proposal received in time"
+	CauseNoPrevotes      = "no +2/3 prevote"
+	CauseNoPrecommits    = "no +2/3 precommit"
+	CauseUnknown         = "unknown"
+)
+
+//ExplainRounds replays entries and returns a verdict for every round transition within a height.
+//Status holds the node's state at the end of the abandoned round, which the verdict is based on
+func ExplainRounds(entries []LogEntry, nodes []Node) ([]RoundChange, error) {
+	var err error
+	var status Status
+	var bpArr []string
+	var changes []RoundChange
+	var invalid bool
+
+	status.Proposal = "No"
+
+	myIP := findMyIP(entries)
+
+	for _, entry := range entries {
+		prev := status
+
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return changes, err
+		}
+
+		if isInvalidProposal(entry) {
+			invalid = true
+		}
+
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+			if status.Height == prev.Height && status.Round > prev.Round {
+				change := RoundChange{
+					Date:      entry.Date,
+					Time:      entry.Time,
+					Height:    status.Height,
+					FromRound: prev.Round,
+					ToRound:   status.Round,
+					Status:    copyStatus(prev),
+				}
+
+				change.Cause, change.Missing, err = explainRound(prev, invalid, nodes)
+				if err != nil {
+					return changes, err
+				}
+
+				changes = append(changes, change)
+			}
+			invalid = false
+		}
+	}
+	return changes, err
+}
+
+//*********************************************************************following functions belong to ExplainRounds***********************************************
+
+//explainRound decides why the round described by status (its final state) failed, naming validators whose votes never arrived
+func explainRound(status Status, invalid bool, nodes []Node) (string, []string, error) {
+	if invalid {
+		return CauseInvalidProposal, nil, nil
+	}
+
+	if status.Proposal == "No" {
+		return CauseNoProposal, nil, nil
+	}
+
+	if countBlockVotes(status.PreVotes)*3 <= len(nodes)*2 {
+		missing, err := missingVoters(status.PreVotes, nodes)
+		return CauseNoPrevotes, missing, err
+	}
+
+	if countBlockVotes(status.PreCommits)*3 <= len(nodes)*2 {
+		missing, err := missingVoters(status.PreCommits, nodes)
+		return CauseNoPrecommits, missing, err
+	}
+
+	return CauseUnknown, nil, nil
+}
+
+//counts non-nil votes, sent or received
+func countBlockVotes(votes []string) int {
+	count := 0
+
+	for _, vote := range votes {
+		if vote == "X" || vote == "O" {
+			count++
+		}
+	}
+	return count
+}
+
+//names the nodes with no vote in a vote array
+func missingVoters(votes []string, nodes []Node) ([]string, error) {
+	var missing []string
+
+	for _, node := range nodes {
+		index, err := strconv.Atoi(node.Index)
+		if err != nil {
+			return missing, err
+		}
+
+		if index < len(votes) && votes[index] == "_" {
+			missing = append(missing, node.Name)
+		}
+	}
+	return missing, nil
+}
+
+//check for a proposal rejected by this node
+func isInvalidProposal(entry LogEntry) bool {
+	if strings.Contains(entry.Descrip, "ProposalBlock is invalid") {
+		return true
+	}
+	return entry.Descrip == "Error with msg" && strings.Contains(entry.Other["type"], "Proposal")
+}
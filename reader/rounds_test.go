@@ -0,0 +1,84 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestExplainRounds(t *testing.T) {
+	nodes := NODES
+
+	testCases := []struct {
+		entries []reader.LogEntry
+		cause   string
+		missing []string
+	}{
+		{
+			//no proposal
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:03.002", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			reader.CauseNoProposal,
+			nil,
+		},
+		{
+			//invalid proposal outranks everything else
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "enterPrevote: ProposalBlock is invalid", "consensus", map[string]string{"err": "wrong app hash"}},
+				reader.LogEntry{"", "08-14", "00:00:03.002", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			reader.CauseInvalidProposal,
+			nil,
+		},
+		{
+			//prevotes missing from three validators (nil prevotes don't count toward +2/3)
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 000000000000 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:03.002", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			reader.CauseNoPrevotes,
+			[]string{nodes[2].Name, nodes[3].Name, nodes[4].Name},
+		},
+		{
+			//precommits missing from two validators
+			[]reader.LogEntry{
+				reader.LogEntry{"", "08-14", "00:00:00.001", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+				reader.LogEntry{"", "08-14", "00:00:00.001", "Received complete proposal block", "consensus", map[string]string{"height": "1"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{2:87708B69426D 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "", map[string]string{"msg": "[Vote Vote{3:B7AACD67CE2E 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 1/00/2(Precommit) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/2(Precommit) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "", map[string]string{"msg": "[Vote Vote{2:87708B69426D 1/00/2(Precommit) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+				reader.LogEntry{"", "08-14", "00:00:03.002", "enterNewRound(1/1). Current: 1/0/RoundStepPrecommitWait", "consensus", map[string]string{}},
+			},
+			reader.CauseNoPrecommits,
+			[]string{nodes[3].Name, nodes[4].Name},
+		},
+	}
+
+	for _, testCase := range testCases {
+		changes, err := reader.ExplainRounds(testCase.entries, nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(changes) != 1 {
+			t.Fatalf("expected 1 round change, received %d", len(changes))
+		}
+
+		if changes[0].Cause != testCase.cause || !reflect.DeepEqual(changes[0].Missing, testCase.missing) {
+			t.Errorf("expected %s %s, received %s %s", testCase.cause, testCase.missing, changes[0].Cause, changes[0].Missing)
+		}
+	}
+}
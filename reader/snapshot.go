@@ -0,0 +1,314 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Snapshot is a node's consensus state as captured by the /dump_consensus_state RPC: its round state as a Status, about
+//when it was taken (the latest time in the dump: a vote's timestamp, the commit or the round's start), and what it knew
+//of each peer's round state. Dumps give times in UTC, while logs use the node's local clock, so Taken is left in UTC
+//for the caller to convert (see At)
+type Snapshot struct {
+	Status Status
+	Taken  time.Time
+	Peers  []PeerState
+}
+
+//PeerState is a peer's round state as a node saw it. Bit arrays are as dumped, eg. BA{4:xx_x}
+type PeerState struct {
+	Name       string
+	Height     int
+	Round      int
+	Step       string
+	Proposal   bool
+	BlockParts string
+	Prevotes   string
+	Precommits string
+}
+
+//StatusDiff is a field where the Status rebuilt from logs and a snapshot's disagree
+type StatusDiff struct {
+	Field    string
+	Logs     string
+	Snapshot string
+}
+
+//stepNames are the round steps by number, as older dumps give them
+var stepNames = []string{"", "NewHeight", "NewRound", "Propose", "Prevote", "PrevoteWait", "Precommit", "PrecommitWait", "Commit"}
+
+//ParseSnapshot reads /dump_consensus_state JSON (the whole RPC response, or just its result). Votes in the current
+//round are marked as received (X, or Y for nil) by validator index; block parts are counted from the dump's bit array
+//if it has one, and left nil otherwise. Field names are matched ignoring case and underscores, and numbers may be
+//quoted, as dumps have varied between versions
+func ParseSnapshot(data []byte, nodes []Node) (Snapshot, error) {
+	var snapshot Snapshot
+	var root map[string]interface{}
+
+	err := json.Unmarshal(data, &root)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if result, ok := jsonField(root, "result").(map[string]interface{}); ok {
+		root = result
+	}
+
+	rs, ok := jsonField(root, "round_state").(map[string]interface{})
+	if !ok {
+		return snapshot, fmt.Errorf("no round_state in snapshot")
+	}
+
+	status := Status{Height: jsonInt(jsonField(rs, "height")), Round: jsonInt(jsonField(rs, "round")), Step: jsonStep(jsonField(rs, "step")), Proposal: "No"}
+	if jsonField(rs, "proposal") != nil {
+		status.Proposal = "Yes"
+	}
+
+	if parts, ok := jsonField(rs, "proposal_block_parts").(string); ok {
+		status.BlockParts = []string{}
+		for _, bit := range bitArray(parts) {
+			if bit == "X" {
+				status.BlockParts = append(status.BlockParts, "X")
+			}
+		}
+	}
+
+	var latest time.Time
+	for _, key := range []string{"start_time", "commit_time"} {
+		if at, err := time.Parse(time.RFC3339Nano, jsonString(jsonField(rs, key))); err == nil && at.After(latest) && at.Year() > 1 {
+			latest = at
+		}
+	}
+
+	status.PreVotes, status.PreCommits = emptyRow(len(nodes)), emptyRow(len(nodes))
+	status.XPreVotes, status.XPreCommits = emptyRow(len(nodes)), emptyRow(len(nodes))
+
+	rounds, _ := jsonField(rs, "votes").([]interface{})
+	for _, round := range rounds {
+		votes, ok := round.(map[string]interface{})
+		if !ok || jsonInt(jsonField(votes, "round")) != status.Round {
+			continue
+		}
+
+		for _, kind := range []string{"prevotes", "precommits"} {
+			row := status.PreVotes
+			if kind == "precommits" {
+				row = status.PreCommits
+			}
+
+			list, _ := jsonField(votes, kind).([]interface{})
+			for i, vote := range list {
+				str := jsonString(vote)
+				if i >= len(row) || str == "" || strings.HasPrefix(str, "nil-") {
+					continue
+				}
+
+				row[i] = "X"
+				if strings.Contains(str, "000000000000") {
+					row[i] = "Y"
+				}
+
+				if at := voteTime(str); at.After(latest) {
+					latest = at
+				}
+			}
+
+			//without the votes themselves, the bit array still says whose arrived
+			if len(list) == 0 {
+				for i, bit := range bitArray(jsonString(jsonField(votes, kind+"_bit_array"))) {
+					if i < len(row) {
+						row[i] = bit
+					}
+				}
+			}
+		}
+	}
+
+	snapshot.Status = status
+	snapshot.Taken = latest
+
+	snapshot.Peers = parsePeerStates(jsonField(root, "peer_round_states"), nodes)
+	if snapshot.Peers == nil {
+		snapshot.Peers = parsePeerStates(jsonField(root, "peers"), nodes)
+	}
+	return snapshot, nil
+}
+
+//At gives the date and time the snapshot was taken on the clock of a log offset from UTC by the given amount (eg. -4h
+//for a node logging in EDT), or empty strings if the dump had no times in it
+func (snapshot Snapshot) At(offset time.Duration) (string, string) {
+	if snapshot.Taken.IsZero() {
+		return "", ""
+	}
+
+	local := snapshot.Taken.UTC().Add(offset)
+	return local.Format("01-02"), local.Format("15:04:05.000")
+}
+
+//DiffStatus lists where the Status rebuilt from logs disagrees with a snapshot's. The snapshot can't tell our own votes
+//from others', so sent votes (O, N) count as received (X, Y); block parts are only compared if the snapshot has them
+func DiffStatus(logs Status, snapshot Status, nodes []Node) []StatusDiff {
+	var diffs []StatusDiff
+
+	add := func(field string, a string, b string) {
+		if a != b {
+			diffs = append(diffs, StatusDiff{field, a, b})
+		}
+	}
+
+	add("height", strconv.Itoa(logs.Height), strconv.Itoa(snapshot.Height))
+	add("round", strconv.Itoa(logs.Round), strconv.Itoa(snapshot.Round))
+	add("step", logs.Step, snapshot.Step)
+	add("proposal", logs.Proposal, snapshot.Proposal)
+
+	if snapshot.BlockParts != nil {
+		add("block parts", strconv.Itoa(len(logs.BlockParts)), strconv.Itoa(len(snapshot.BlockParts)))
+	}
+
+	received := strings.NewReplacer("O", "X", "N", "Y")
+	for i := range nodes {
+		name := nodeName(nodes, i)
+		if i < len(logs.PreVotes) && i < len(snapshot.PreVotes) {
+			add("prevote from "+name, received.Replace(logs.PreVotes[i]), received.Replace(snapshot.PreVotes[i]))
+		}
+		if i < len(logs.PreCommits) && i < len(snapshot.PreCommits) {
+			add("precommit from "+name, received.Replace(logs.PreCommits[i]), received.Replace(snapshot.PreCommits[i]))
+		}
+	}
+	return diffs
+}
+
+//*********************************************************************following functions belong to ParseSnapshot***********************************************
+
+//parsePeerStates reads peers' round states, dumped either as a map of peer key to state or as a list of peers, each
+//with its address and state
+func parsePeerStates(value interface{}, nodes []Node) []PeerState {
+	var peers []PeerState
+
+	add := func(name string, state interface{}) {
+		obj, ok := state.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		//newer dumps wrap the round state in the peer's state
+		if inner, ok := jsonField(obj, "peer_state").(map[string]interface{}); ok {
+			obj = inner
+		}
+		if inner, ok := jsonField(obj, "round_state").(map[string]interface{}); ok {
+			obj = inner
+		}
+
+		proposal, _ := jsonField(obj, "proposal").(bool)
+		peers = append(peers, PeerState{name, jsonInt(jsonField(obj, "height")), jsonInt(jsonField(obj, "round")), jsonStep(jsonField(obj, "step")), proposal,
+			jsonString(jsonField(obj, "proposal_block_parts")), jsonString(jsonField(obj, "prevotes")), jsonString(jsonField(obj, "precommits"))})
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, state := range value {
+			add(findProposer(nodes, key), state)
+		}
+	case []interface{}:
+		for _, peer := range value {
+			obj, ok := peer.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			//eg. "ID@172.31.39.83:46656"
+			address := jsonString(jsonField(obj, "node_address"))
+			ip := strings.Split(address[strings.LastIndex(address, "@")+1:], ":")[0]
+			name := findName(nodes, ip)
+			if name == "" {
+				name = address
+			}
+			add(name, obj)
+		}
+	}
+
+	//maps come back in no particular order, so peers are listed by name
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	return peers
+}
+
+//jsonField looks a key up ignoring case and underscores, so round_state matches RoundState
+func jsonField(obj map[string]interface{}, key string) interface{} {
+	norm := func(str string) string {
+		return strings.ToLower(strings.Replace(str, "_", "", -1))
+	}
+
+	for k, v := range obj {
+		if norm(k) == norm(key) {
+			return v
+		}
+	}
+	return nil
+}
+
+//jsonInt reads a number, quoted or not
+func jsonInt(value interface{}) int {
+	switch value := value.(type) {
+	case float64:
+		return int(value)
+	case string:
+		i, _ := strconv.Atoi(value)
+		return i
+	}
+	return 0
+}
+
+func jsonString(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return ""
+}
+
+//jsonStep reads a step as a number or a name (eg. 4 or "RoundStepPrevote"), naming it as Status does. The waits after
+//prevote and precommit don't change Status' step, so they read as the step they follow
+func jsonStep(value interface{}) string {
+	step := jsonString(value)
+	if n, ok := value.(float64); ok && n >= 0 && int(n) < len(stepNames) {
+		step = stepNames[int(n)]
+	} else if n, err := strconv.Atoi(step); err == nil && n >= 0 && n < len(stepNames) {
+		step = stepNames[n]
+	}
+
+	step = strings.TrimPrefix(step, "RoundStep")
+	return strings.TrimSuffix(step, "Wait")
+}
+
+//bitArray reads a bit array as dumped, eg. "BA{4:xx_x} 3/4", into X and _ marks
+func bitArray(str string) []string {
+	var bits []string
+
+	start := strings.Index(str, ":")
+	end := strings.Index(str, "}")
+	if !strings.HasPrefix(str, "BA{") || start < 0 || end < start {
+		return bits
+	}
+
+	for _, bit := range str[start+1 : end] {
+		if bit == 'x' {
+			bits = append(bits, "X")
+		} else {
+			bits = append(bits, "_")
+		}
+	}
+	return bits
+}
+
+//voteTime reads the timestamp newer dumps give votes, eg. "Vote{0:ADDR 1/00/1(Prevote) HASH SIG @ 2017-08-14T04:33:01.2Z}"
+func voteTime(vote string) time.Time {
+	at := strings.TrimSuffix(vote[strings.LastIndex(vote, "@ ")+1:], "}")
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(at))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,91 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	nodes := NODES
+
+	dump := `{"jsonrpc": "2.0", "id": "", "result": {
+		"round_state": {
+			"height": 12, "round": 1, "step": 4,
+			"start_time": "2017-08-14T04:33:00.000Z", "commit_time": "0001-01-01T00:00:00Z",
+			"proposal": {"height": 12, "round": 1}, "proposal_block_parts": "BA{2:xx}",
+			"votes": [
+				{"round": 0, "prevotes": ["nil-Vote", "nil-Vote", "nil-Vote", "nil-Vote", "nil-Vote"]},
+				{"round": 1,
+					"prevotes": ["Vote{0:2A3A16F15BEE 12/01/1(Prevote) 0E9ED1D3E9A0 /5F5F5F5F5F5F/ @ 2017-08-14T04:33:01.250Z}", "nil-Vote", "Vote{2:87708B69426D 12/01/1(Prevote) 000000000000 /5F5F5F5F5F5F/ @ 2017-08-14T04:33:01.100Z}", "nil-Vote", "nil-Vote"],
+					"precommits_bit_array": "BA{5:___x_} 1/5"}
+			]
+		},
+		"peer_round_states": {
+			"87708B69426D0000": {"Height": "12", "Round": "1", "Step": "RoundStepPrecommitWait", "Proposal": true, "ProposalBlockParts": "BA{2:xx}", "Prevotes": "BA{5:x_x__}", "Precommits": "BA{5:_____}"},
+			"3D3074F7A7D00000": {"Height": "11", "Round": "0", "Step": "RoundStepCommit", "Proposal": false, "ProposalBlockParts": "", "Prevotes": "", "Precommits": ""}
+		}
+	}}`
+
+	snapshot, err := reader.ParseSnapshot([]byte(dump), nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := reader.Snapshot{
+		reader.Status{12, 1, "Prevote", "Yes", []string{"X", "X"},
+			[]string{"X", "_", "Y", "_", "_"}, []string{"_", "_", "_", "X", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}},
+		time.Date(2017, 8, 14, 4, 33, 1, 250000000, time.UTC),
+		[]reader.PeerState{
+			reader.PeerState{nodes[2].Name, 12, 1, "Precommit", true, "BA{2:xx}", "BA{5:x_x__}", "BA{5:_____}"},
+			reader.PeerState{nodes[1].Name, 11, 0, "Commit", false, "", "", ""},
+		},
+	}
+
+	if !reflect.DeepEqual(snapshot, exp) {
+		t.Errorf("expected %v, got %v", exp, snapshot)
+	}
+
+	//dumps are in UTC; logs are on the node's clock
+	if date, clock := snapshot.At(-5 * time.Hour); date != "08-13" || clock != "23:33:01.250" {
+		t.Errorf("expected 08-13 23:33:01.250, got %s %s", date, clock)
+	}
+
+	_, err = reader.ParseSnapshot([]byte(`{"result": {}}`), nodes)
+	if err == nil {
+		t.Error("expected an error for a dump without a round state")
+	}
+
+	//a step out of range has no name
+	snapshot, err = reader.ParseSnapshot([]byte(`{"round_state": {"height": 1, "round": 0, "step": -1}}`), nodes)
+	if err != nil || snapshot.Status.Step != "" {
+		t.Errorf("expected no step, got %s (%v)", snapshot.Status.Step, err)
+	}
+}
+
+func TestDiffStatus(t *testing.T) {
+	nodes := NODES
+
+	logs := reader.Status{12, 1, "Prevote", "Yes", []string{"X"},
+		[]string{"O", "_", "Y", "X", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}}
+	snapshot := reader.Status{12, 1, "Precommit", "Yes", []string{"X", "X"},
+		[]string{"X", "_", "Y", "_", "_"}, []string{"_", "_", "_", "X", "_"}, []string{"_", "_", "_", "_", "_"}, []string{"_", "_", "_", "_", "_"}}
+
+	exp := []reader.StatusDiff{
+		reader.StatusDiff{"step", "Prevote", "Precommit"},
+		reader.StatusDiff{"block parts", "1", "2"},
+		reader.StatusDiff{"prevote from " + nodes[3].Name, "X", "_"},
+		reader.StatusDiff{"precommit from " + nodes[3].Name, "_", "X"},
+	}
+
+	diffs := reader.DiffStatus(logs, snapshot, nodes)
+	if !reflect.DeepEqual(diffs, exp) {
+		t.Errorf("expected %v, got %v", exp, diffs)
+	}
+
+	if diffs := reader.DiffStatus(logs, logs, nodes); diffs != nil {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+}
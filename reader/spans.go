@@ -0,0 +1,163 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Span is a stretch of a node's consensus: a height, holding its rounds, each holding its steps (Propose, Prevote,
+//Precommit, Commit). Votes and block parts received (and votes sent) are events on the step they arrived in
+type Span struct {
+	Name     string
+	Kind     string
+	Height   int
+	Round    int
+	Start    time.Time
+	End      time.Time
+	Events   []SpanEvent
+	Children []Span
+}
+
+//SpanEvent is a vote or block part arriving at the node
+type SpanEvent struct {
+	Name  string
+	Time  time.Time
+	Attrs map[string]string
+}
+
+//span kinds
+const (
+	SpanHeight = "height"
+	SpanRound  = "round"
+	SpanStep   = "step"
+)
+
+//GetSpans replays entries and splits them into spans wherever the height, round or step changes, as newStep finds them.
+//The last spans end with the log
+func GetSpans(entries []LogEntry, nodes []Node) ([]Span, error) {
+	var err error
+	var status Status
+	var bpArr []string
+	var spans []Span
+	var height, round, step Span
+	var last time.Time
+
+	status.Proposal = "No"
+
+	myIP := findMyIP(entries)
+
+	closeStep := func(t time.Time) {
+		step.End = t
+		round.Children = append(round.Children, step)
+	}
+
+	closeRound := func(t time.Time) {
+		closeStep(t)
+		round.End = t
+		height.Children = append(height.Children, round)
+	}
+
+	closeHeight := func(t time.Time) {
+		closeRound(t)
+		height.End = t
+		spans = append(spans, height)
+	}
+
+	for _, entry := range entries {
+		prev := status
+
+		status, bpArr, err = updateStatus(status, entry, nodes, myIP, bpArr)
+		if err != nil {
+			return spans, err
+		}
+
+		last, err = EntryTime(entry)
+		if err != nil {
+			return spans, err
+		}
+
+		if status.Height != prev.Height {
+			if prev.Height != 0 {
+				closeHeight(last)
+			}
+			height = Span{Name: fmt.Sprintf("Height %d", status.Height), Kind: SpanHeight, Height: status.Height, Start: last}
+			round = newRoundSpan(status, last)
+			step = newStepSpan(status, last)
+		} else if status.Round != prev.Round {
+			closeRound(last)
+			round = newRoundSpan(status, last)
+			step = newStepSpan(status, last)
+		} else if status.Step != prev.Step {
+			closeStep(last)
+			step = newStepSpan(status, last)
+		}
+
+		if status.Height == 0 {
+			continue
+		}
+
+		event, ok := spanEvent(entry, nodes)
+		if ok {
+			event.Time = last
+			step.Events = append(step.Events, event)
+		}
+	}
+
+	if status.Height != 0 {
+		closeHeight(last)
+	}
+	return spans, err
+}
+
+//*********************************************************************following functions belong to GetSpans***********************************************
+
+func newRoundSpan(status Status, start time.Time) Span {
+	return Span{Name: fmt.Sprintf("Round %d", status.Round), Kind: SpanRound, Height: status.Height, Round: status.Round, Start: start}
+}
+
+func newStepSpan(status Status, start time.Time) Span {
+	return Span{Name: status.Step, Kind: SpanStep, Height: status.Height, Round: status.Round, Start: start}
+}
+
+//spanEvent turns a vote or block part, received or sent, into an event
+func spanEvent(entry LogEntry, nodes []Node) (SpanEvent, bool) {
+	msg := entry.Other["msg"]
+
+	if entry.Descrip == "Signed and pushed vote" {
+		msg = entry.Other["vote"]
+	} else if entry.Descrip != "Receive" {
+		return SpanEvent{}, false
+	}
+
+	attrs := make(map[string]string)
+
+	for _, node := range nodes {
+		if entry.Descrip == "Receive" && node.Ip == peerIP(entry) {
+			attrs["from"] = node.Name
+		}
+	}
+
+	if strings.Contains(msg, "Vote{") {
+		vote, err := parseVote(msg)
+		if err != nil {
+			return SpanEvent{}, false
+		}
+
+		attrs["validator"] = nodeName(nodes, vote.Index)
+		attrs["type"] = vote.Type
+		attrs["nil"] = strconv.FormatBool(vote.Nil)
+
+		return SpanEvent{Name: vote.Type, Attrs: attrs}, true
+	}
+
+	if strings.Contains(msg, "BlockPart") {
+		_, _, part := parseBlockPart(msg)
+		attrs["part"] = strconv.Itoa(part)
+
+		return SpanEvent{Name: "BlockPart", Attrs: attrs}, true
+	}
+
+	return SpanEvent{}, false
+}
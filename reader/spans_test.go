@@ -0,0 +1,63 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetSpans(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "enterPropose(1/0). Current: 1/0/RoundStepNewRound", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.002", "Receive", "consensus", map[string]string{"msg": "[BlockPart H:1 R:0 P:Part{#0\n  Bytes: 010101066A65...\n}]", "src": "Peer{MConn{172.31.39.83:46656} 4F85D81F23AB out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.004", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]", "src": "Peer{MConn{172.31.39.83:46656} 4F85D81F23AB out}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.005", "enterNewRound(1/1). Current: 1/0/RoundStepPrevote", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.006", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.007", "enterPropose(2/0). Current: 2/0/RoundStepNewRound", "consensus", map[string]string{}},
+	}
+
+	spans, err := reader.GetSpans(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//each height should hold its rounds, and each round its steps, ending where the next begins (or the log ends)
+	var names []string
+	var ends []string
+	for _, height := range spans {
+		names = append(names, height.Name)
+		ends = append(ends, height.End.Format("05.000"))
+		for _, round := range height.Children {
+			names = append(names, round.Name)
+			ends = append(ends, round.End.Format("05.000"))
+			for _, step := range round.Children {
+				names = append(names, step.Name)
+				ends = append(ends, step.End.Format("05.000"))
+			}
+		}
+	}
+
+	expNames := []string{"Height 1", "Round 0", "NewRound", "Propose", "Prevote", "Round 1", "NewRound", "Height 2", "Round 0", "NewRound", "Propose"}
+	expEnds := []string{"00.006", "00.005", "00.001", "00.003", "00.005", "00.006", "00.006", "00.007", "00.007", "00.007", "00.007"}
+
+	if !reflect.DeepEqual(expNames, names) || !reflect.DeepEqual(expEnds, ends) {
+		t.Errorf("expected %v ending %v, received %v ending %v", expNames, expEnds, names, ends)
+	}
+
+	//arrivals are events on the step they arrived in
+	propose := spans[0].Children[0].Children[1].Events
+	if len(propose) != 1 || propose[0].Name != "BlockPart" || propose[0].Attrs["part"] != "0" || propose[0].Attrs["from"] != nodes[1].Name {
+		t.Errorf("expected block part 0 from %s, received %v", nodes[1].Name, propose)
+	}
+
+	prevote := spans[0].Children[0].Children[2].Events
+	if len(prevote) != 1 || prevote[0].Name != "Prevote" || prevote[0].Attrs["validator"] != nodes[1].Name {
+		t.Errorf("expected prevote from %s, received %v", nodes[1].Name, prevote)
+	}
+}
@@ -0,0 +1,181 @@
+package reader
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Sync is a node's fast sync, from its first blockchain entry (StartDate StartTime) until it switched to consensus at
+//Height (EndDate and EndTime are empty if it was still syncing when the log ends)
+type Sync struct {
+	StartDate string
+	StartTime string
+	EndDate   string
+	EndTime   string
+	Height    int
+	Duration  time.Duration
+	Rates     []SyncRate
+	Peers     []SyncPeer
+	Timeouts  []SyncEvent
+	Bans      []SyncEvent
+}
+
+//SyncRate is the sync's progress as the node logged it: its height, the highest height a peer reported, and the rate
+//in blocks/sec since it last logged
+type SyncRate struct {
+	Date          string
+	Time          string
+	Height        int
+	MaxPeerHeight int
+	Rate          float64
+}
+
+//SyncPeer is a peer the node synced from: the last height it reported, how many blocks it sent, and how often it
+//timed out. Name is the peer's IP if it isn't in nodes.json
+type SyncPeer struct {
+	Name     string
+	Height   int
+	Blocks   int
+	Timeouts int
+	Banned   bool
+}
+
+//SyncEvent is a peer timing out, or being dropped by the blockchain reactor, with the error given
+type SyncEvent struct {
+	Date string
+	Time string
+	Name string
+	Err  string
+}
+
+//GetSync follows a node's fast sync through its blockchain module: "Fast Sync Rate" for progress, block and status
+//responses for the peers used, "SendTimeout" for requests that timed out, peers the blockchain reactor stopped while
+//syncing, and "Time to switch to consensus reactor!" for the end. ok is false if the node never fast synced
+func GetSync(entries []LogEntry, nodes []Node) (Sync, bool, error) {
+	var sync Sync
+	var started bool
+	var startT time.Time
+
+	peers := make(map[string]*SyncPeer)
+	var order []string
+	keys := make(map[string]string)
+
+	peer := func(name string) *SyncPeer {
+		if _, ok := peers[name]; !ok {
+			peers[name] = &SyncPeer{Name: name}
+			order = append(order, name)
+		}
+		return peers[name]
+	}
+
+	for _, entry := range entries {
+		if !started {
+			if entry.Module != "blockchain" {
+				continue
+			}
+			started = true
+			sync.StartDate, sync.StartTime = entry.Date, entry.Time
+
+			var err error
+			startT, err = EntryTime(entry)
+			if err != nil {
+				return sync, true, err
+			}
+		}
+
+		//peers stopped once the node has switched to consensus aren't the sync's concern
+		if sync.EndDate != "" {
+			break
+		}
+
+		switch {
+		case entry.Descrip == "Fast Sync Rate":
+			rate, _ := strconv.ParseFloat(entry.Other["blocks/s"], 64)
+			height, _ := strconv.Atoi(entry.Other["height"])
+			maxHeight, _ := strconv.Atoi(entry.Other["max_peer_height"])
+			sync.Rates = append(sync.Rates, SyncRate{entry.Date, entry.Time, height, maxHeight, rate})
+
+		case entry.Descrip == "Receive" && entry.Module == "blockchain":
+			name := syncPeerName(entry.Other["src"], nodes, keys)
+			msg := strings.TrimSuffix(strings.TrimPrefix(entry.Other["msg"], "["), "]")
+
+			if strings.HasPrefix(msg, "bcStatusResponseMessage ") {
+				peer(name).Height = leadingInt(strings.TrimPrefix(msg, "bcStatusResponseMessage "))
+			} else if strings.HasPrefix(msg, "bcBlockResponseMessage ") {
+				peer(name).Blocks++
+			}
+
+		case entry.Descrip == "SendTimeout", entry.Module == "blockchain" && strings.Contains(strings.ToLower(entry.Descrip), "timed out"):
+			name := syncPeerName(entry.Other["peer"], nodes, keys)
+			peer(name).Timeouts++
+
+			reason := entry.Other["reason"]
+			if reason == "" {
+				reason = entry.Descrip
+			}
+			sync.Timeouts = append(sync.Timeouts, SyncEvent{entry.Date, entry.Time, name, reason})
+
+		case isSyncStop(entry):
+			name := syncPeerName(entry.Other["peer"], nodes, keys)
+			peer(name).Banned = true
+
+			reason := entry.Other["err"]
+			if reason == "" {
+				reason = entry.Descrip
+			}
+			sync.Bans = append(sync.Bans, SyncEvent{entry.Date, entry.Time, name, reason})
+
+		case entry.Descrip == "Time to switch to consensus reactor!", entry.Descrip == "SwitchToConsensus":
+			sync.EndDate, sync.EndTime = entry.Date, entry.Time
+			if height, err := strconv.Atoi(entry.Other["height"]); err == nil {
+				sync.Height = height
+			}
+
+			endT, err := EntryTime(entry)
+			if err != nil {
+				return sync, true, err
+			}
+			sync.Duration = endT.Sub(startT)
+		}
+	}
+
+	for _, name := range order {
+		sync.Peers = append(sync.Peers, *peers[name])
+	}
+	return sync, started, nil
+}
+
+//*********************************************************************following functions belong to GetSync***********************************************
+
+//isSyncStop checks for the blockchain reactor dropping a peer. The switch logs the stop under p2p, with the reactor's
+//error: "BlockchainReactor Timeout" for a peer too slow to send blocks, or "BlockchainReactor validation error: ..." for
+//one that sent a bad block. Peers stopped for anything else (eg. EOF) aren't the sync's doing
+func isSyncStop(entry LogEntry) bool {
+	return entry.Descrip == "Stopping peer for error" && entry.Module == "p2p" && strings.HasPrefix(entry.Other["err"], "BlockchainReactor")
+}
+
+//syncPeerName names a peer logged as "Peer{MConn{ip:port} KEY out}" or by its full key, as the block pool logs them.
+//Keys seen alongside an IP are remembered, so a peer logged either way gets the same name
+func syncPeerName(peer string, nodes []Node, keys map[string]string) string {
+	ip, _ := parsePeer(peer)
+
+	if fields := strings.Fields(peer); len(fields) == 3 && strings.HasPrefix(peer, "Peer{") {
+		keys[strings.ToUpper(fields[1])] = ip
+	} else if peer != "" && !strings.Contains(peer, ".") {
+		for key, keyIP := range keys {
+			if strings.HasPrefix(strings.ToUpper(peer), key) {
+				ip = keyIP
+				break
+			}
+		}
+		if ip == peer {
+			return findProposer(nodes, peer)
+		}
+	}
+
+	if name := findName(nodes, ip); name != "" {
+		return name
+	}
+	return ip
+}
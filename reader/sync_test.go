@@ -0,0 +1,60 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetSync(t *testing.T) {
+	nodes := NODES
+
+	src1 := "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"
+	src2 := "Peer{MConn{10.0.0.9:46656} 99AA00BB11CC in}"
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.32.72:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:01.000", "Receive", "blockchain", map[string]string{"src": src1, "msg": "[bcStatusResponseMessage 500]"}},
+		reader.LogEntry{"", "08-14", "00:00:01.001", "Receive", "blockchain", map[string]string{"src": src2, "msg": "[bcStatusResponseMessage 480]"}},
+		reader.LogEntry{"", "08-14", "00:00:01.100", "Receive", "blockchain", map[string]string{"src": src1, "msg": "[bcBlockResponseMessage 1]"}},
+		reader.LogEntry{"", "08-14", "00:00:01.200", "Receive", "blockchain", map[string]string{"src": src2, "msg": "[bcBlockResponseMessage 2]"}},
+		reader.LogEntry{"", "08-14", "00:00:02.000", "SendTimeout", "blockchain", map[string]string{"peer": "99AA00BB11CCDD", "reason": "peer did not send us anything"}},
+		reader.LogEntry{"", "08-14", "00:00:02.001", "Stopping peer for error", "p2p", map[string]string{"peer": src2, "err": "BlockchainReactor Timeout"}},
+		//a peer dropping its connection isn't the reactor stopping it, nor is a ban elsewhere
+		reader.LogEntry{"", "08-14", "00:00:03.000", "Stopping peer for error", "p2p", map[string]string{"peer": src1, "err": "EOF"}},
+		reader.LogEntry{"", "08-14", "00:00:03.500", "Peer banned from address book", "addrbook", map[string]string{"peer": src1}},
+		reader.LogEntry{"", "08-14", "00:00:05.000", "Fast Sync Rate", "blockchain", map[string]string{"height": "101", "max_peer_height": "500", "blocks/s": "25.5"}},
+		reader.LogEntry{"", "08-14", "00:00:10.000", "Time to switch to consensus reactor!", "blockchain", map[string]string{"height": "500"}},
+		reader.LogEntry{"", "08-14", "00:00:11.000", "Stopping peer for error", "p2p", map[string]string{"peer": src1, "err": "EOF"}},
+	}
+
+	sync, ok, err := reader.GetSync(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected a fast sync")
+	}
+
+	exp := reader.Sync{
+		"08-14", "00:00:01.000", "08-14", "00:00:10.000", 500, 9 * time.Second,
+		[]reader.SyncRate{reader.SyncRate{"08-14", "00:00:05.000", 101, 500, 25.5}},
+		[]reader.SyncPeer{
+			reader.SyncPeer{nodes[1].Name, 500, 1, 0, false},
+			reader.SyncPeer{"10.0.0.9", 480, 1, 1, true},
+		},
+		[]reader.SyncEvent{reader.SyncEvent{"08-14", "00:00:02.000", "10.0.0.9", "peer did not send us anything"}},
+		[]reader.SyncEvent{reader.SyncEvent{"08-14", "00:00:02.001", "10.0.0.9", "BlockchainReactor Timeout"}},
+	}
+
+	if !reflect.DeepEqual(exp, sync) {
+		t.Errorf("expected %v, received %v", exp, sync)
+	}
+
+	_, ok, err = reader.GetSync(entries[:1], nodes)
+	if err != nil || ok {
+		t.Errorf("expected no fast sync, received %v %v", ok, err)
+	}
+}
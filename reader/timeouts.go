@@ -0,0 +1,156 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Timeout is a consensus timeout the node scheduled: its kind (propose, prevote, precommit or commit), the height and
+//round it was for, and how long it was set for. Date and Time are when it fired, or when it was scheduled if the node
+//moved on before it could fire
+type Timeout struct {
+	Height   int
+	Round    int
+	Kind     string
+	Duration time.Duration
+	Date     string
+	Time     string
+	Fired    bool
+}
+
+//TimeoutConfig is the consensus timeouts a node was configured with, by config.toml key (eg. timeout_propose,
+//timeout_propose_delta)
+type TimeoutConfig map[string]time.Duration
+
+//timeoutKinds names the timeout each step waits out; the commit timeout is scheduled as the next height starts
+var timeoutKinds = map[string]string{
+	"RoundStepPropose":       "propose",
+	"RoundStepPrevoteWait":   "prevote",
+	"RoundStepPrecommitWait": "precommit",
+	"RoundStepNewHeight":     "commit",
+}
+
+//TimeoutKinds are the kinds of timeout in the order consensus waits them out
+var TimeoutKinds = []string{"propose", "prevote", "precommit", "commit"}
+
+//GetTimeouts lists every timeout the node scheduled ("Scheduled timeout") or saw fire ("Timed out" from the ticker,
+//or "Received tock" as consensus handles it, the only one of the three a WAL has), in the order they were scheduled.
+//Zero length timeouts (eg. skipping the rest of timeout_commit) and steps without a timeout aren't included
+func GetTimeouts(entries []LogEntry) ([]Timeout, error) {
+	var timeouts []Timeout
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		var field string
+
+		switch entry.Descrip {
+		case "Scheduled timeout", "Timed out":
+			field = "dur"
+		case "Received tock":
+			field = "timeout"
+		default:
+			continue
+		}
+
+		kind, ok := timeoutKinds[entry.Other["step"]]
+		if !ok {
+			continue
+		}
+
+		dur, err := time.ParseDuration(entry.Other[field])
+		if err != nil {
+			return timeouts, fmt.Errorf("bad timeout %q at %s %s: %v", entry.Other[field], entry.Date, entry.Time, err)
+		}
+		if dur == 0 {
+			continue
+		}
+
+		height, err := strconv.Atoi(entry.Other["height"])
+		if err != nil {
+			return timeouts, err
+		}
+
+		round, err := strconv.Atoi(entry.Other["round"])
+		if err != nil {
+			return timeouts, err
+		}
+
+		key := fmt.Sprintf("%d/%d/%s", height, round, kind)
+		i, seen := index[key]
+		if !seen {
+			timeouts = append(timeouts, Timeout{height, round, kind, dur, entry.Date, entry.Time, false})
+			i = len(timeouts) - 1
+			index[key] = i
+		}
+
+		//the ticker and consensus both log a timeout firing; the first is when it fired
+		if entry.Descrip != "Scheduled timeout" && !timeouts[i].Fired {
+			timeouts[i].Fired = true
+			timeouts[i].Date, timeouts[i].Time = entry.Date, entry.Time
+		}
+	}
+	return timeouts, nil
+}
+
+//TimeoutsAt lists the timeouts that fired in the node's round at the given date and time, as GetStatus finds it. The
+//commit timeout is scheduled for the next height, but waited out while Status is still in this height's Commit
+func TimeoutsAt(entries []LogEntry, status Status, date string, time string) ([]Timeout, error) {
+	var fired []Timeout
+	var watch = false
+
+	//the same cut-off as GetStatus
+	end := len(entries)
+	for i, entry := range entries {
+		if entry.Date == date && strings.Contains(entry.Time, time) {
+			watch = true
+		}
+
+		if watch == true && !strings.Contains(entry.Time, time) {
+			end = i
+			break
+		}
+	}
+
+	timeouts, err := GetTimeouts(entries[:end])
+	if err != nil {
+		return fired, err
+	}
+
+	for _, timeout := range timeouts {
+		if !timeout.Fired {
+			continue
+		}
+
+		if (timeout.Kind != "commit" && timeout.Height == status.Height && timeout.Round == status.Round) || (timeout.Kind == "commit" && timeout.Height == status.Height+1) {
+			fired = append(fired, timeout)
+		}
+	}
+	return fired, nil
+}
+
+//Expected is how long a timeout of the given kind should be in the given round: its base, plus its delta for every
+//round after the first. ok is false if the config doesn't set the kind's base
+func (config TimeoutConfig) Expected(kind string, round int) (time.Duration, bool) {
+	base, ok := config["timeout_"+kind]
+	if !ok {
+		return 0, false
+	}
+	return base + time.Duration(round)*config["timeout_"+kind+"_delta"], true
+}
+
+//Misconfigured reports whether a timeout disagrees with the config, along with the duration the config expects. The
+//commit timeout is scheduled for whatever's left of timeout_commit once the block is committed, so it's only wrong if
+//it's longer than that; the others must match exactly
+func (config TimeoutConfig) Misconfigured(timeout Timeout) (time.Duration, bool) {
+	expected, ok := config.Expected(timeout.Kind, timeout.Round)
+	if !ok {
+		return expected, false
+	}
+
+	if timeout.Kind == "commit" {
+		return expected, timeout.Duration > expected
+	}
+	return expected, timeout.Duration != expected
+}
@@ -0,0 +1,115 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+var TIMEOUT_ENTRIES = []reader.LogEntry{
+	reader.LogEntry{"I", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+	reader.LogEntry{"D", "08-14", "00:00:00.001", "Scheduled timeout", "consensus", map[string]string{"dur": "3s", "height": "1", "round": "0", "step": "RoundStepPropose"}},
+	reader.LogEntry{"D", "08-14", "00:00:03.001", "Timed out", "consensus", map[string]string{"dur": "3s", "height": "1", "round": "0", "step": "RoundStepPropose"}},
+	reader.LogEntry{"D", "08-14", "00:00:03.002", "Received tock", "consensus", map[string]string{"timeout": "3s", "height": "1", "round": "0", "step": "RoundStepPropose"}},
+	reader.LogEntry{"I", "08-14", "00:00:03.003", "enterPrevote(1/0). Current: 1/0/RoundStepPropose", "consensus", map[string]string{}},
+	reader.LogEntry{"D", "08-14", "00:00:03.100", "Scheduled timeout", "consensus", map[string]string{"dur": "1s", "height": "1", "round": "0", "step": "RoundStepPrevoteWait"}},
+	reader.LogEntry{"I", "08-14", "00:00:03.200", "enterPrecommit(1/0). Current: 1/0/RoundStepPrevoteWait", "consensus", map[string]string{}},
+	reader.LogEntry{"I", "08-14", "00:00:03.300", "enterCommit(1/0). Current: 1/0/RoundStepPrecommit", "consensus", map[string]string{}},
+	reader.LogEntry{"D", "08-14", "00:00:03.301", "Scheduled timeout", "consensus", map[string]string{"dur": "998.3ms", "height": "2", "round": "0", "step": "RoundStepNewHeight"}},
+	reader.LogEntry{"D", "08-14", "00:00:04.301", "Timed out", "consensus", map[string]string{"dur": "998.3ms", "height": "2", "round": "0", "step": "RoundStepNewHeight"}},
+	reader.LogEntry{"D", "08-14", "00:00:04.302", "Scheduled timeout", "consensus", map[string]string{"dur": "0s", "height": "2", "round": "0", "step": "RoundStepNewRound"}},
+	reader.LogEntry{"I", "08-14", "00:00:04.303", "enterNewRound(2/0). Current: 2/0/RoundStepNewHeight", "consensus", map[string]string{}},
+}
+
+func TestGetTimeouts(t *testing.T) {
+	timeouts, err := reader.GetTimeouts(TIMEOUT_ENTRIES)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []reader.Timeout{
+		reader.Timeout{1, 0, "propose", 3 * time.Second, "08-14", "00:00:03.001", true},
+		reader.Timeout{1, 0, "prevote", time.Second, "08-14", "00:00:03.100", false},
+		reader.Timeout{2, 0, "commit", 998300 * time.Microsecond, "08-14", "00:00:04.301", true},
+	}
+
+	if !reflect.DeepEqual(timeouts, exp) {
+		t.Errorf("expected %v, got %v", exp, timeouts)
+	}
+}
+
+func TestTimeoutsAt(t *testing.T) {
+	nodes := NODES
+
+	testCases := []struct {
+		time string
+		exp  []reader.Timeout
+	}{
+		{"00:00:03.003", []reader.Timeout{reader.Timeout{1, 0, "propose", 3 * time.Second, "08-14", "00:00:03.001", true}}},
+		{"00:00:04.301", []reader.Timeout{reader.Timeout{1, 0, "propose", 3 * time.Second, "08-14", "00:00:03.001", true}, reader.Timeout{2, 0, "commit", 998300 * time.Microsecond, "08-14", "00:00:04.301", true}}},
+		{"00:00:04.303", nil},
+	}
+
+	for _, testCase := range testCases {
+		status, err := reader.GetStatus(TIMEOUT_ENTRIES, nodes, "08-14", testCase.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		timeouts, err := reader.TimeoutsAt(TIMEOUT_ENTRIES, status, "08-14", testCase.time)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(timeouts, testCase.exp) {
+			t.Errorf("at %s expected %v, got %v", testCase.time, testCase.exp, timeouts)
+		}
+	}
+}
+
+func TestExpected(t *testing.T) {
+	config := reader.TimeoutConfig{"timeout_propose": 3 * time.Second, "timeout_propose_delta": 500 * time.Millisecond, "timeout_commit": time.Second}
+
+	testCases := []struct {
+		kind  string
+		round int
+		exp   time.Duration
+		ok    bool
+	}{
+		{"propose", 0, 3 * time.Second, true},
+		{"propose", 2, 4 * time.Second, true},
+		{"commit", 1, time.Second, true},
+		{"prevote", 0, 0, false},
+	}
+
+	for _, testCase := range testCases {
+		expected, ok := config.Expected(testCase.kind, testCase.round)
+		if expected != testCase.exp || ok != testCase.ok {
+			t.Errorf("%s round %d: expected %v %v, got %v %v", testCase.kind, testCase.round, testCase.exp, testCase.ok, expected, ok)
+		}
+	}
+}
+
+func TestMisconfigured(t *testing.T) {
+	config := reader.TimeoutConfig{"timeout_propose": 3 * time.Second, "timeout_commit": time.Second}
+
+	testCases := []struct {
+		timeout reader.Timeout
+		wrong   bool
+	}{
+		{reader.Timeout{1, 0, "propose", 3 * time.Second, "08-14", "00:00:03.001", true}, false},
+		{reader.Timeout{1, 0, "propose", 2 * time.Second, "08-14", "00:00:02.001", true}, true},
+		//the commit timeout only runs for what's left of timeout_commit
+		{reader.Timeout{2, 0, "commit", 998300 * time.Microsecond, "08-14", "00:00:04.301", true}, false},
+		{reader.Timeout{2, 0, "commit", 2 * time.Second, "08-14", "00:00:05.301", true}, true},
+		{reader.Timeout{1, 0, "prevote", time.Second, "08-14", "00:00:04.100", true}, false},
+	}
+
+	for _, testCase := range testCases {
+		if _, wrong := config.Misconfigured(testCase.timeout); wrong != testCase.wrong {
+			t.Errorf("%s %v: expected misconfigured %v, got %v", testCase.timeout.Kind, testCase.timeout.Duration, testCase.wrong, wrong)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package reader
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+//Topology is the cluster's P2P graph over time, put together from each node's view of its own connections. Where both
+//ends of an edge have logs covering a moment, the edge is up only if both say so; past the end (or before the start) of
+//a log, the other end's view is taken, so a node that crashed drops out of the graph once its peers notice
+type Topology struct {
+	Nodes []string
+	views map[string]*peerView
+}
+
+//Partition is a period when the cluster was split into Components with no path between them. End is zero if the split
+//hadn't healed when the logs end
+type Partition struct {
+	Start      time.Time
+	End        time.Time
+	Components [][]string
+}
+
+//peerView is what one node's log shows of its connections, by peer name
+type peerView struct {
+	start time.Time
+	end   time.Time
+	conns map[string][]connSpan
+}
+
+//connSpan is a connection from start until end, or through the end of the log if end is zero
+type connSpan struct {
+	start time.Time
+	end   time.Time
+}
+
+//GetTopology builds the graph from the logs of the nodes in a cluster (any one log is enough, but each log added fills
+//in its node's side). Connections come from peers being added and stopped; a peer we receive messages from before its
+//connection was logged was connected before the log began, until it's stopped
+func GetTopology(logs [][]LogEntry, nodes []Node) (*Topology, error) {
+	topology := &Topology{views: make(map[string]*peerView)}
+	seen := make(map[string]bool)
+
+	for _, entries := range logs {
+		if len(entries) == 0 {
+			continue
+		}
+
+		name := findName(nodes, findMyIP(entries))
+		if name == "" {
+			continue
+		}
+
+		view, err := newPeerView(entries, nodes)
+		if err != nil {
+			return topology, err
+		}
+
+		topology.views[name] = view
+		seen[name] = true
+		for peer := range view.conns {
+			seen[peer] = true
+		}
+	}
+
+	for _, node := range nodes {
+		if seen[node.Name] {
+			topology.Nodes = append(topology.Nodes, node.Name)
+		}
+	}
+	return topology, nil
+}
+
+//Connected reports whether nodes a and b had a connection up at the given time
+func (topology *Topology) Connected(a string, b string, at time.Time) bool {
+	var sides [][2]string
+	for _, side := range [][2]string{{a, b}, {b, a}} {
+		if _, ok := topology.views[side[0]]; ok {
+			sides = append(sides, side)
+		}
+	}
+
+	//only the logs that cover the moment have a say, unless none do
+	var covering [][2]string
+	for _, side := range sides {
+		if topology.views[side[0]].covers(at) {
+			covering = append(covering, side)
+		}
+	}
+	if len(covering) > 0 {
+		sides = covering
+	}
+
+	for _, side := range sides {
+		if !topology.views[side[0]].connected(side[1], at) {
+			return false
+		}
+	}
+	return len(sides) > 0
+}
+
+//Edges lists the pairs of nodes connected at the given time
+func (topology *Topology) Edges(at time.Time) [][2]string {
+	var edges [][2]string
+
+	for i, a := range topology.Nodes {
+		for _, b := range topology.Nodes[i+1:] {
+			if topology.Connected(a, b, at) {
+				edges = append(edges, [2]string{a, b})
+			}
+		}
+	}
+	return edges
+}
+
+//Components groups the nodes into sets with a path between every member at the given time, in nodes.json order
+func (topology *Topology) Components(at time.Time) [][]string {
+	var components [][]string
+	placed := make(map[string]bool)
+
+	for _, start := range topology.Nodes {
+		if placed[start] {
+			continue
+		}
+		placed[start] = true
+
+		component := []string{}
+		queue := []string{start}
+		for len(queue) > 0 {
+			a := queue[0]
+			queue = queue[1:]
+			component = append(component, a)
+
+			for _, b := range topology.Nodes {
+				if !placed[b] && topology.Connected(a, b, at) {
+					placed[b] = true
+					queue = append(queue, b)
+				}
+			}
+		}
+
+		sort.Slice(component, func(i, j int) bool { return topology.order(component[i]) < topology.order(component[j]) })
+		components = append(components, component)
+	}
+	return components
+}
+
+//Formed returns when the cluster first came together as a single component, or false if it never did
+func (topology *Topology) Formed() (time.Time, bool) {
+	for _, at := range topology.changes() {
+		if len(topology.Components(at)) == 1 {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+//Partitions lists each split of the cluster, counted from when it first came together (before then, nodes are still
+//dialing each other). A split whose components change is reported as a new partition
+func (topology *Topology) Partitions() []Partition {
+	var partitions []Partition
+
+	formed, ok := topology.Formed()
+	if !ok {
+		return partitions
+	}
+
+	open := false
+	for _, at := range topology.changes() {
+		if at.Before(formed) {
+			continue
+		}
+
+		components := topology.Components(at)
+
+		if open && (len(components) == 1 || !reflect.DeepEqual(components, partitions[len(partitions)-1].Components)) {
+			partitions[len(partitions)-1].End = at
+			open = false
+		}
+
+		if !open && len(components) > 1 {
+			partitions = append(partitions, Partition{at, time.Time{}, components})
+			open = true
+		}
+	}
+	return partitions
+}
+
+//*********************************************************************following functions belong to Topology***********************************************
+
+//newPeerView reads one node's connections to the other validators from its log
+func newPeerView(entries []LogEntry, nodes []Node) (*peerView, error) {
+	view := &peerView{conns: make(map[string][]connSpan)}
+
+	var err error
+	view.start, err = EntryTime(entries[0])
+	if err != nil {
+		return view, err
+	}
+	view.end, err = EntryTime(entries[len(entries)-1])
+	if err != nil {
+		return view, err
+	}
+
+	peers, err := GetPeers(entries, nodes, 0, 0, 0)
+	if err != nil {
+		return view, err
+	}
+
+	for _, conn := range peers.Conns {
+		if conn.Name == "" {
+			continue
+		}
+
+		var span connSpan
+		span.start, err = EntryTime(LogEntry{Date: conn.StartDate, Time: conn.StartTime})
+		if err != nil {
+			return view, err
+		}
+		if conn.EndDate != "" {
+			span.end, err = EntryTime(LogEntry{Date: conn.EndDate, Time: conn.EndTime})
+			if err != nil {
+				return view, err
+			}
+		}
+		view.conns[conn.Name] = append(view.conns[conn.Name], span)
+	}
+
+	//a peer heard from or stopped before it was added was connected when the log began, until it was stopped (or added)
+	early := make(map[string]bool)
+	stops := make(map[string]time.Time)
+
+	for _, entry := range entries {
+		var name string
+		switch entry.Descrip {
+		case "Receive":
+			name = findName(nodes, peerIP(entry))
+		case "Stopping peer for error", "Stopping peer", "Stopping Peer":
+			name = peerRecord(0, entry, nodes).Name
+		}
+		if name == "" {
+			continue
+		}
+
+		entryT, err := EntryTime(entry)
+		if err != nil {
+			return view, err
+		}
+
+		if spans := view.conns[name]; len(spans) > 0 && !entryT.Before(spans[0].start) {
+			continue
+		}
+
+		early[name] = true
+		if _, ok := stops[name]; !ok && entry.Descrip != "Receive" {
+			stops[name] = entryT
+		}
+	}
+
+	for name := range early {
+		span := connSpan{view.start, stops[name]}
+		if spans := view.conns[name]; span.end.IsZero() && len(spans) > 0 {
+			span.end = spans[0].start
+		}
+		view.conns[name] = append([]connSpan{span}, view.conns[name]...)
+	}
+
+	return view, nil
+}
+
+//covers reports whether the log runs over the given time
+func (view *peerView) covers(at time.Time) bool {
+	return !at.Before(view.start) && !at.After(view.end)
+}
+
+//connected reports whether the log shows a connection to the peer at the given time, or at the nearest end of the
+//log for times outside it
+func (view *peerView) connected(peer string, at time.Time) bool {
+	if at.Before(view.start) {
+		at = view.start
+	} else if at.After(view.end) {
+		at = view.end
+	}
+
+	for _, span := range view.conns[peer] {
+		if !at.Before(span.start) && (span.end.IsZero() || at.Before(span.end)) {
+			return true
+		}
+	}
+	return false
+}
+
+//changes lists, in order, every time the graph might have changed
+func (topology *Topology) changes() []time.Time {
+	var times []time.Time
+
+	for _, view := range topology.views {
+		times = append(times, view.start, view.end)
+		for _, spans := range view.conns {
+			for _, span := range spans {
+				times = append(times, span.start)
+				if !span.end.IsZero() {
+					times = append(times, span.end)
+				}
+			}
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var unique []time.Time
+	for _, at := range times {
+		if len(unique) == 0 || !at.Equal(unique[len(unique)-1]) {
+			unique = append(unique, at)
+		}
+	}
+	return unique
+}
+
+//order is a node's position in Nodes
+func (topology *Topology) order(name string) int {
+	for i, node := range topology.Nodes {
+		if node == name {
+			return i
+		}
+	}
+	return len(topology.Nodes)
+}
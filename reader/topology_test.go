@@ -0,0 +1,85 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestTopology(t *testing.T) {
+	nodes := NODES
+
+	logs := [][]reader.LogEntry{
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.44.161:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:05.000", "Stopping peer for error", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:08.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:10.000", "enterNewRound(9/0). Current: 9/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		},
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.39.83:46656)"}},
+			//connected to node0 before the log began
+			reader.LogEntry{"", "08-14", "00:00:00.500", "Receive", "consensus", map[string]string{"msg": "[HasVote VI:0 V:{1/00/1}]", "src": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:05.000", "Stopping peer for error", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:08.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.36.95:46656} 87708B69426D out}"}},
+			reader.LogEntry{"", "08-14", "00:00:10.000", "enterNewRound(9/0). Current: 9/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		},
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:05.000", "Stopping peer for error", "p2p", map[string]string{"peer": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:05.000", "Stopping peer for error", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:08.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:08.000", "Added peer", "p2p", map[string]string{"peer": "Peer{MConn{172.31.39.83:46656} 3D3074F7A7D0 out}"}},
+			reader.LogEntry{"", "08-14", "00:00:10.000", "enterNewRound(9/0). Current: 9/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		},
+	}
+
+	topology, err := reader.GetTopology(logs, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(clock string) time.Time {
+		at, err := reader.EntryTime(reader.LogEntry{Date: "08-14", Time: clock})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+
+	if exp := []string{nodes[0].Name, nodes[1].Name, nodes[2].Name}; !reflect.DeepEqual(exp, topology.Nodes) {
+		t.Errorf("expected nodes %v, received %v", exp, topology.Nodes)
+	}
+
+	edgeCases := []struct {
+		clock string
+		exp   [][2]string
+	}{
+		{"00:00:00.700", [][2]string(nil)},
+		{"00:00:03.000", [][2]string{{nodes[0].Name, nodes[1].Name}, {nodes[0].Name, nodes[2].Name}, {nodes[1].Name, nodes[2].Name}}},
+		{"00:00:06.000", [][2]string{{nodes[0].Name, nodes[1].Name}}},
+	}
+
+	for _, testCase := range edgeCases {
+		edges := topology.Edges(at(testCase.clock))
+		if !reflect.DeepEqual(testCase.exp, edges) {
+			t.Errorf("at %s expected edges %v, received %v", testCase.clock, testCase.exp, edges)
+		}
+	}
+
+	partitions := topology.Partitions()
+	exp := []reader.Partition{
+		reader.Partition{at("00:00:05.000"), at("00:00:08.000"), [][]string{{nodes[0].Name, nodes[1].Name}, {nodes[2].Name}}},
+	}
+
+	if !reflect.DeepEqual(exp, partitions) {
+		t.Errorf("expected %v, received %v", exp, partitions)
+	}
+}
@@ -0,0 +1,169 @@
+package reader
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Tendermint splits blocks into parts of this many bytes; logs truncate part bytes, so a truncated part is assumed full
+const blockPartSize = 65536
+
+//Traffic holds, for one interval, the number of messages and estimated bytes received from each node (indexed as in nodes.json) on each channel
+type Traffic struct {
+	Time  string
+	Msgs  map[string][]int
+	Bytes map[string][]int
+}
+
+//Talker totals the traffic received from one node
+type Talker struct {
+	Name     string
+	Msgs     int
+	Bytes    int
+	Channels map[string]int
+}
+
+//RateDrop marks an interval in which a node's message rate fell sharply from the interval before
+type RateDrop struct {
+	Time   string
+	Name   string
+	Before int
+	After  int
+}
+
+//GetTraffic counts received messages and estimates their size per node, per channel, over intervals of length dur.
+//Bytes are only known for block parts and mempool txs; other messages count toward Msgs alone
+func GetTraffic(entries []LogEntry, nodes []Node, dur time.Duration, stD string, stT string, enD string, enT string) ([]Traffic, error) {
+	var trafficArr []Traffic
+
+	traffic := Traffic{Msgs: map[string][]int{}, Bytes: map[string][]int{}}
+
+	read := func(entry LogEntry) error {
+		if entry.Descrip != "Receive" {
+			return nil
+		}
+
+		index, err := nodeIndex(peerIP(entry), nodes)
+		if err != nil || index < 0 {
+			return err
+		}
+
+		ch := entry.Other["chId"]
+		if _, ok := traffic.Msgs[ch]; !ok {
+			traffic.Msgs[ch] = make([]int, len(nodes))
+			traffic.Bytes[ch] = make([]int, len(nodes))
+		}
+
+		traffic.Msgs[ch][index]++
+		traffic.Bytes[ch][index] += msgBytes(entry)
+
+		return nil
+	}
+
+	flush := func(timeStamp string) {
+		traffic.Time = timeStamp
+		trafficArr = append(trafficArr, traffic)
+		traffic = Traffic{Msgs: map[string][]int{}, Bytes: map[string][]int{}}
+	}
+
+	err := walkIntervals(entries, dur, stD, stT, enD, enT, read, flush)
+
+	return trafficArr, err
+}
+
+//TopTalkers totals traffic over every interval, busiest node first
+func TopTalkers(trafficArr []Traffic, nodes []Node) ([]Talker, error) {
+	var talkers []Talker
+
+	for _, node := range nodes {
+		talker := Talker{Name: node.Name, Channels: map[string]int{}}
+
+		index, err := strconv.Atoi(node.Index)
+		if err != nil {
+			return talkers, err
+		}
+
+		for _, traffic := range trafficArr {
+			for ch, msgs := range traffic.Msgs {
+				talker.Msgs += msgs[index]
+				talker.Bytes += traffic.Bytes[ch][index]
+				talker.Channels[ch] += msgs[index]
+			}
+		}
+
+		talkers = append(talkers, talker)
+	}
+
+	sort.SliceStable(talkers, func(i, j int) bool {
+		return talkers[i].Msgs > talkers[j].Msgs
+	})
+
+	return talkers, nil
+}
+
+//RateDrops finds intervals in which a node sent at most drop (eg. 0.5) times as many messages as in the interval before.
+//Intervals with fewer than minMsgs messages are too quiet to judge a drop from
+func RateDrops(trafficArr []Traffic, nodes []Node, drop float64, minMsgs int) ([]RateDrop, error) {
+	var drops []RateDrop
+
+	for _, node := range nodes {
+		index, err := strconv.Atoi(node.Index)
+		if err != nil {
+			return drops, err
+		}
+
+		for i := 1; i < len(trafficArr); i++ {
+			before := totalMsgs(trafficArr[i-1], index)
+			after := totalMsgs(trafficArr[i], index)
+
+			if before >= minMsgs && float64(after) <= float64(before)*drop {
+				drops = append(drops, RateDrop{Time: trafficArr[i].Time, Name: node.Name, Before: before, After: after})
+			}
+		}
+	}
+	return drops, nil
+}
+
+//*********************************************************************following functions belong to GetTraffic***********************************************
+
+//totalMsgs sums one node's messages on every channel in an interval
+func totalMsgs(traffic Traffic, index int) int {
+	total := 0
+
+	for _, msgs := range traffic.Msgs {
+		total += msgs[index]
+	}
+	return total
+}
+
+//msgBytes estimates the size of a received block part or mempool tx from the bytes printed in its msg
+func msgBytes(entry LogEntry) int {
+	msg := entry.Other["msg"]
+
+	switch msgKind(entry) {
+	case "BlockPart":
+		bytesParse := strings.SplitN(msg, "Bytes:", 2)
+		if len(bytesParse) < 2 {
+			return 0
+		}
+
+		//rendered logs escape the newline after the bytes
+		hex := strings.Fields(strings.Replace(bytesParse[1], `\n`, " ", -1))
+		if len(hex) == 0 {
+			return 0
+		}
+
+		if strings.HasSuffix(hex[0], "...") {
+			return blockPartSize
+		}
+		return len(hex[0]) / 2
+
+	case "Tx":
+		tx := strings.TrimSuffix(strings.TrimPrefix(msg, "[TxMessage "), "]")
+		tx = strings.TrimSuffix(strings.TrimPrefix(tx, "Tx{"), "}")
+		return len(tx) / 2
+	}
+	return 0
+}
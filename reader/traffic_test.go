@@ -0,0 +1,67 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetTraffic(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.36.95:46656)"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}", "chId": "33", "msg": "[BlockPart H:1 R:0 P:Part{#0\n  Bytes: 010101066A65...\n  Proof: SimpleProof{\n    Aunts: []\n  }\n}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.44.161:46656} 4F85D81F23AB out}", "chId": "34", "msg": "[Vote Vote{0:2A3A16F15BEE 1/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.001", "Receive", "mempool",
+			map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}", "chId": "48", "msg": "[TxMessage Tx{0A0B0C}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "consensus",
+			map[string]string{"src": "Peer{MConn{172.31.46.4:58661} 1BF7CA4820CD out}", "chId": "33", "msg": `[BlockPart H:1 R:0 P:Part{#1\n  Bytes: 010101066A65...\n  Proof: SimpleProof{\n    Aunts: []\n  }\n}]`}},
+		reader.LogEntry{"", "08-14", "00:00:00.003", "Receive", "mempool",
+			map[string]string{"src": "Peer{MConn{172.31.39.83:46656} 1BF7CA4820CD out}", "chId": "48", "msg": "[TxMessage Tx{0A0B}]"}},
+	}
+
+	trafficArr, err := reader.GetTraffic(entries, nodes, 2*time.Millisecond, "08-14", "00:00:00.000", "08-14", "00:00:00.003")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trafficArr) != 2 {
+		t.Fatalf("expected 2 intervals, received %d", len(trafficArr))
+	}
+
+	expMsgs := map[string][]int{"33": {1, 0, 0, 0, 0}, "34": {1, 0, 0, 0, 0}, "48": {0, 1, 0, 0, 0}}
+	expBytes := map[string][]int{"33": {65536, 0, 0, 0, 0}, "34": {0, 0, 0, 0, 0}, "48": {0, 3, 0, 0, 0}}
+
+	if !reflect.DeepEqual(expMsgs, trafficArr[0].Msgs) || !reflect.DeepEqual(expBytes, trafficArr[0].Bytes) {
+		t.Errorf("expected %v %v, received %v %v", expMsgs, expBytes, trafficArr[0].Msgs, trafficArr[0].Bytes)
+	}
+
+	//block parts read from a rendered log carry escaped newlines
+	if trafficArr[1].Bytes["33"][3] != 65536 {
+		t.Errorf("expected a full block part from %s, received %d bytes", nodes[3].Name, trafficArr[1].Bytes["33"][3])
+	}
+
+	talkers, err := reader.TopTalkers(trafficArr, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if talkers[0].Name != nodes[0].Name || talkers[0].Msgs != 2 || talkers[0].Bytes != 65536 {
+		t.Errorf("expected %s to top talkers with 2 msgs, received %v", nodes[0].Name, talkers[0])
+	}
+
+	drops, err := reader.RateDrops(trafficArr, nodes, 0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expDrops := []reader.RateDrop{{Time: "08-14 00:00:00.002", Name: nodes[0].Name, Before: 2, After: 0}}
+	if !reflect.DeepEqual(expDrops, drops) {
+		t.Errorf("expected %v, received %v", expDrops, drops)
+	}
+}
@@ -0,0 +1,172 @@
+package reader
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//TxTrace follows a transaction across the cluster, from the first node to see it until it was included in a block.
+//Txs are identified as the mempool logs them (hex). From is the peer that delivered it to the first node, or empty if
+//it was submitted there directly. Height is 0 and Wait is unknown until some node removes the tx after a commit
+type TxTrace struct {
+	Tx      string
+	Date    string
+	Time    string
+	Node    string
+	From    string
+	Height  int
+	IncDate string
+	IncTime string
+	Wait    time.Duration
+	Nodes   []TxNode
+}
+
+//TxNode is a tx's time in one node's mempool: when it arrived and from whom, its CheckTx result (OK, or the rejected
+//response), how many rechecks it sat through, and the height it was removed after (0 if it never was)
+type TxNode struct {
+	Node     string
+	Date     string
+	Time     string
+	From     string
+	Result   string
+	Rechecks int
+	Height   int
+}
+
+//GetTxs traces every tx in the mempools of the given logs: "Receive" of a TxMessage from a peer, CheckTx ("Added good
+//transaction", "Rejected bad transaction"), rechecks ("Recheck txs") and removal ("Removed tx"), which marks the tx
+//included in the last block the node committed. Traces are ordered by when a tx was first seen
+func GetTxs(logs [][]LogEntry, nodes []Node) ([]TxTrace, error) {
+	var traces []TxTrace
+	byTx := make(map[string]int)
+
+	firstSeen := make(map[string]time.Time)
+	included := make(map[string]time.Time)
+
+	for _, entries := range logs {
+		me := findName(nodes, findMyIP(entries))
+
+		views := make(map[string]*TxNode)
+		var order []string
+		var height int
+		var commitT time.Time
+
+		for _, entry := range entries {
+			if entry.Module != "mempool" && entry.Module != "consensus" && entry.Module != "state" {
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(entry.Descrip, "Finalizing commit of block"), entry.Descrip == "Executed block":
+				h, err := strconv.Atoi(entry.Other["height"])
+				if err != nil || h == height {
+					continue
+				}
+				height = h
+				commitT, err = EntryTime(entry)
+				if err != nil {
+					return traces, err
+				}
+				continue
+
+			case entry.Descrip == "Recheck txs":
+				for _, tx := range order {
+					if views[tx].Result == "OK" && views[tx].Height == 0 {
+						views[tx].Rechecks++
+					}
+				}
+				continue
+			}
+
+			tx := entryTx(entry)
+			if tx == "" {
+				continue
+			}
+
+			view, ok := views[tx]
+			if !ok {
+				view = &TxNode{Node: me, Date: entry.Date, Time: entry.Time}
+				views[tx] = view
+				order = append(order, tx)
+
+				entryT, err := EntryTime(entry)
+				if err != nil {
+					return traces, err
+				}
+
+				if i, ok := byTx[tx]; !ok {
+					byTx[tx] = len(traces)
+					traces = append(traces, TxTrace{Tx: tx, Date: entry.Date, Time: entry.Time, Node: me})
+					firstSeen[tx] = entryT
+				} else if entryT.Before(firstSeen[tx]) {
+					traces[i].Date, traces[i].Time, traces[i].Node, traces[i].From = entry.Date, entry.Time, me, ""
+					firstSeen[tx] = entryT
+				}
+			}
+
+			switch entry.Descrip {
+			case "Receive":
+				if view.From == "" && view.Result == "" {
+					view.From = findName(nodes, peerIP(entry))
+					if view.From == "" {
+						view.From = peerIP(entry)
+					}
+				}
+			case "Added good transaction":
+				view.Result = "OK"
+			case "Rejected bad transaction":
+				view.Result = entry.Other["res"]
+			case "Removed tx":
+				if view.Height == 0 && height > 0 {
+					view.Height = height
+
+					trace := &traces[byTx[tx]]
+					if trace.Height == 0 || commitT.Before(included[tx]) {
+						trace.Height = height
+						trace.IncDate, trace.IncTime = commitT.Format("01-02"), commitT.Format("15:04:05.000")
+						included[tx] = commitT
+					}
+				}
+			}
+		}
+
+		for _, tx := range order {
+			trace := &traces[byTx[tx]]
+			trace.Nodes = append(trace.Nodes, *views[tx])
+			if trace.Node == me && trace.Date == views[tx].Date && trace.Time == views[tx].Time {
+				trace.From = views[tx].From
+			}
+		}
+	}
+
+	for i := range traces {
+		if incT, ok := included[traces[i].Tx]; ok {
+			traces[i].Wait = incT.Sub(firstSeen[traces[i].Tx])
+		}
+	}
+
+	sort.SliceStable(traces, func(i, j int) bool { return firstSeen[traces[i].Tx].Before(firstSeen[traces[j].Tx]) })
+	return traces, nil
+}
+
+//*********************************************************************following functions belong to GetTxs***********************************************
+
+//entryTx reads the tx from a mempool entry, eg. tx=0A0B0C or msg="[TxMessage Tx{0A0B0C}]", or returns "" for other entries
+func entryTx(entry LogEntry) string {
+	if entry.Module != "mempool" {
+		return ""
+	}
+
+	if entry.Descrip == "Receive" {
+		if msgKind(entry) != "Tx" {
+			return ""
+		}
+		tx := strings.TrimSuffix(strings.TrimPrefix(entry.Other["msg"], "[TxMessage "), "]")
+		return strings.ToUpper(strings.TrimSuffix(strings.TrimPrefix(tx, "Tx{"), "}"))
+	}
+
+	tx := strings.TrimSuffix(strings.TrimPrefix(entry.Other["tx"], "Tx{"), "}")
+	return strings.ToUpper(tx)
+}
@@ -0,0 +1,50 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetTxs(t *testing.T) {
+	nodes := NODES
+
+	logs := [][]reader.LogEntry{
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.44.161:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.000", "Added good transaction", "mempool", map[string]string{"tx": "0a0b01", "res": "&{CheckTx:code:OK}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.500", "Rejected bad transaction", "mempool", map[string]string{"tx": "0A0B02", "res": "&{CheckTx:code:BadNonce}"}},
+			reader.LogEntry{"", "08-14", "00:00:02.000", "Recheck txs", "mempool", map[string]string{"numtxs": "1"}},
+			reader.LogEntry{"", "08-14", "00:00:03.000", "Finalizing commit of block with 1 txs", "consensus", map[string]string{"height": "5"}},
+			reader.LogEntry{"", "08-14", "00:00:03.010", "Removed tx", "mempool", map[string]string{"tx": "0A0B01"}},
+		},
+		{
+			reader.LogEntry{"", "08-14", "00:00:00.000", "Starting DefaultListener", "", map[string]string{"impl": "Listener(@172.31.39.83:46656)"}},
+			reader.LogEntry{"", "08-14", "00:00:01.100", "Receive", "mempool", map[string]string{"msg": "[TxMessage Tx{0A0B01}]", "src": "Peer{MConn{172.31.44.161:46656} 2A3A16F15BEE out}"}},
+			reader.LogEntry{"", "08-14", "00:00:01.101", "Added good transaction", "mempool", map[string]string{"tx": "0A0B01", "res": "&{CheckTx:code:OK}"}},
+			reader.LogEntry{"", "08-14", "00:00:03.050", "Finalizing commit of block with 1 txs", "consensus", map[string]string{"height": "5"}},
+			reader.LogEntry{"", "08-14", "00:00:03.060", "Removed tx", "mempool", map[string]string{"tx": "0A0B01"}},
+		},
+	}
+
+	traces, err := reader.GetTxs(logs, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []reader.TxTrace{
+		reader.TxTrace{"0A0B01", "08-14", "00:00:01.000", nodes[0].Name, "", 5, "08-14", "00:00:03.000", 2 * time.Second, []reader.TxNode{
+			reader.TxNode{nodes[0].Name, "08-14", "00:00:01.000", "", "OK", 1, 5},
+			reader.TxNode{nodes[1].Name, "08-14", "00:00:01.100", nodes[0].Name, "OK", 0, 5},
+		}},
+		reader.TxTrace{"0A0B02", "08-14", "00:00:01.500", nodes[0].Name, "", 0, "", "", 0, []reader.TxNode{
+			reader.TxNode{nodes[0].Name, "08-14", "00:00:01.500", "", "&{CheckTx:code:BadNonce}", 0, 0},
+		}},
+	}
+
+	if !reflect.DeepEqual(exp, traces) {
+		t.Errorf("expected %v, received %v", exp, traces)
+	}
+}
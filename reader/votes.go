@@ -0,0 +1,129 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//VoteArrival records when a validator's vote first reached the node, relative to the node entering that round
+type VoteArrival struct {
+	Height int
+	Round  int
+	Type   string
+	Name   string
+	Index  int
+	Nil    bool
+	Offset time.Duration
+}
+
+//GetVoteArrivals lists the first arrival of every validator's prevote and precommit for each round the node entered
+//(the node's own votes arrive when it signs them)
+func GetVoteArrivals(entries []LogEntry, nodes []Node) ([]VoteArrival, error) {
+	var err error
+	var arrivals []VoteArrival
+	var seen = make(map[string]bool)
+	var roundStarts = make(map[string]time.Time)
+
+	for _, entry := range entries {
+		if isStep(entry) && strings.Contains(entry.Descrip, "enterNewRound") {
+			height, round, err := heightRound(entry.Descrip)
+			if err != nil {
+				return arrivals, err
+			}
+
+			roundStarts[fmt.Sprintf("%d/%d", height, round)], err = EntryTime(entry)
+			if err != nil {
+				return arrivals, err
+			}
+			continue
+		}
+
+		var msg string
+		if entry.Descrip == "Receive" && strings.Contains(entry.Other["msg"], "Vote Vote") {
+			msg = entry.Other["msg"]
+		} else if entry.Descrip == "Signed and pushed vote" {
+			msg = entry.Other["vote"]
+		} else {
+			continue
+		}
+
+		arrival, err := parseVote(msg)
+		if err != nil {
+			return arrivals, err
+		}
+
+		start, ok := roundStarts[fmt.Sprintf("%d/%d", arrival.Height, arrival.Round)]
+		key := fmt.Sprintf("%d/%d/%s/%d", arrival.Height, arrival.Round, arrival.Type, arrival.Index)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		arrivalTime, err := EntryTime(entry)
+		if err != nil {
+			return arrivals, err
+		}
+
+		arrival.Offset = arrivalTime.Sub(start)
+		arrival.Name = nodeName(nodes, arrival.Index)
+
+		arrivals = append(arrivals, arrival)
+	}
+	return arrivals, err
+}
+
+//*********************************************************************following functions belong to GetVoteArrivals***********************************************
+
+//parseVote reads a vote as logged, eg. "Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}"
+func parseVote(msg string) (VoteArrival, error) {
+	var arrival VoteArrival
+	var err error
+
+	voteParse := strings.SplitN(msg, "Vote{", 2)
+	fields := strings.Fields(voteParse[len(voteParse)-1])
+	if len(fields) < 2 {
+		return arrival, fmt.Errorf("can't read vote %q", msg)
+	}
+
+	arrival.Index, err = strconv.Atoi(strings.Split(fields[0], ":")[0])
+	if err != nil {
+		return arrival, err
+	}
+
+	hrt := strings.Split(fields[1], "/")
+	if len(hrt) < 3 {
+		return arrival, fmt.Errorf("can't read vote %q", msg)
+	}
+
+	arrival.Height, err = strconv.Atoi(hrt[0])
+	if err != nil {
+		return arrival, err
+	}
+
+	arrival.Round, err = strconv.Atoi(hrt[1])
+	if err != nil {
+		return arrival, err
+	}
+
+	typeParse := strings.SplitN(hrt[2], "(", 2)
+	if len(typeParse) < 2 {
+		return arrival, fmt.Errorf("can't read vote %q", msg)
+	}
+
+	arrival.Type = strings.TrimSuffix(typeParse[1], ")")
+	arrival.Nil = strings.Contains(msg, "00000000")
+
+	return arrival, err
+}
+
+//nodeName finds the name of the node at the given bit array index
+func nodeName(nodes []Node, index int) string {
+	for _, node := range nodes {
+		if node.Index == strconv.Itoa(index) {
+			return node.Name
+		}
+	}
+	return strconv.Itoa(index)
+}
@@ -0,0 +1,36 @@
+package reader_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joshkestenberg/t-logs/reader"
+)
+
+func TestGetVoteArrivals(t *testing.T) {
+	nodes := NODES
+
+	entries := []reader.LogEntry{
+		reader.LogEntry{"", "08-14", "00:00:00.000", "enterNewRound(1/0). Current: 1/0/RoundStepNewHeight", "consensus", map[string]string{}},
+		reader.LogEntry{"", "08-14", "00:00:00.250", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.300", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{1:3D3074F7A7D0 1/00/1(Prevote) 4066B75D9AD4 /5FC6C5515A66.../}]"}},
+		reader.LogEntry{"", "08-14", "00:00:00.400", "Signed and pushed vote", "consensus", map[string]string{"height": "1", "round": "0", "vote": "Vote{4:E40892926ECF 1/00/2(Precommit) 000000000000 /C28769ADBAD2.../}"}},
+		reader.LogEntry{"", "08-14", "00:00:00.500", "Receive", "consensus", map[string]string{"msg": "[Vote Vote{0:2A3A16F15BEE 2/00/1(Prevote) 4066B75D9AD4 /202CFFFBC76C.../}]"}},
+	}
+
+	//repeated votes, and votes for rounds the node never entered, are left out
+	expected := []reader.VoteArrival{
+		{Height: 1, Round: 0, Type: "Prevote", Name: nodes[1].Name, Index: 1, Offset: 250 * time.Millisecond},
+		{Height: 1, Round: 0, Type: "Precommit", Name: nodes[4].Name, Index: 4, Nil: true, Offset: 400 * time.Millisecond},
+	}
+
+	arrivals, err := reader.GetVoteArrivals(entries, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, arrivals) {
+		t.Errorf("expected %v, received %v", expected, arrivals)
+	}
+}